@@ -1,121 +1,236 @@
 package openalgo
 
+import "context"
+
+// FundsResponse is the typed response of the funds endpoint
 type FundsResponse struct {
-	Status string `json:"status"`
-	Data   struct {
-		AvailableCash string `json:"availablecash"`
-		Collateral    string `json:"collateral"`
-		M2MRealized   string `json:"m2mrealized"`
-		M2MUnrealized string `json:"m2munrealized"`
-		UtilisedDebits string `json:"utiliseddebits"`
-	} `json:"data"`
+	Status string    `json:"status"`
+	Data   FundsData `json:"data"`
+}
+
+// FundsData holds the account margin figures
+type FundsData struct {
+	AvailableCash  FlexFloat `json:"availablecash"`
+	Collateral     FlexFloat `json:"collateral"`
+	M2MRealized    FlexFloat `json:"m2mrealized"`
+	M2MUnrealized  FlexFloat `json:"m2munrealized"`
+	UtilisedDebits FlexFloat `json:"utiliseddebits"`
 }
 
+// OrderBookResponse is the typed response of the orderbook endpoint
 type OrderBookResponse struct {
-	Status string `json:"status"`
-	Data   struct {
-		Orders []struct {
-			Action       string  `json:"action"`
-			Symbol       string  `json:"symbol"`
-			Exchange     string  `json:"exchange"`
-			OrderID      string  `json:"orderid"`
-			Product      string  `json:"product"`
-			Quantity     string  `json:"quantity"`
-			Price        float64 `json:"price"`
-			PriceType    string  `json:"pricetype"`
-			OrderStatus  string  `json:"order_status"`
-			TriggerPrice float64 `json:"trigger_price"`
-			Timestamp    string  `json:"timestamp"`
-		} `json:"orders"`
-		Statistics struct {
-			TotalBuyOrders       float64 `json:"total_buy_orders"`
-			TotalSellOrders      float64 `json:"total_sell_orders"`
-			TotalCompletedOrders float64 `json:"total_completed_orders"`
-			TotalOpenOrders      float64 `json:"total_open_orders"`
-			TotalRejectedOrders  float64 `json:"total_rejected_orders"`
-		} `json:"statistics"`
-	} `json:"data"`
+	Status string        `json:"status"`
+	Data   OrderBookData `json:"data"`
+}
+
+// OrderBookData holds the orders of the day and their statistics
+type OrderBookData struct {
+	Orders     []OrderBookEntry    `json:"orders"`
+	Statistics OrderBookStatistics `json:"statistics"`
+}
+
+// OrderBookEntry is a single order in the order book
+type OrderBookEntry struct {
+	Action       string    `json:"action"`
+	Symbol       string    `json:"symbol"`
+	Exchange     string    `json:"exchange"`
+	OrderID      string    `json:"orderid"`
+	Product      string    `json:"product"`
+	Quantity     FlexInt   `json:"quantity"`
+	Price        FlexFloat `json:"price"`
+	PriceType    string    `json:"pricetype"`
+	OrderStatus  string    `json:"order_status"`
+	TriggerPrice FlexFloat `json:"trigger_price"`
+	Timestamp    string    `json:"timestamp"`
 }
 
+// OrderBookStatistics summarises the order book
+type OrderBookStatistics struct {
+	TotalBuyOrders       FlexInt `json:"total_buy_orders"`
+	TotalSellOrders      FlexInt `json:"total_sell_orders"`
+	TotalCompletedOrders FlexInt `json:"total_completed_orders"`
+	TotalOpenOrders      FlexInt `json:"total_open_orders"`
+	TotalRejectedOrders  FlexInt `json:"total_rejected_orders"`
+}
+
+// TradeBookResponse is the typed response of the tradebook endpoint
 type TradeBookResponse struct {
-	Status string `json:"status"`
-	Data   []struct {
-		Action       string  `json:"action"`
-		Symbol       string  `json:"symbol"`
-		Exchange     string  `json:"exchange"`
-		OrderID      string  `json:"orderid"`
-		Product      string  `json:"product"`
-		Quantity     float64 `json:"quantity"`
-		AveragePrice float64 `json:"average_price"`
-		Timestamp    string  `json:"timestamp"`
-		TradeValue   float64 `json:"trade_value"`
-	} `json:"data"`
+	Status string  `json:"status"`
+	Data   []Trade `json:"data"`
+}
+
+// Trade is a single executed trade
+type Trade struct {
+	Action       string    `json:"action"`
+	Symbol       string    `json:"symbol"`
+	Exchange     string    `json:"exchange"`
+	OrderID      string    `json:"orderid"`
+	Product      string    `json:"product"`
+	Quantity     FlexInt   `json:"quantity"`
+	AveragePrice FlexFloat `json:"average_price"`
+	Timestamp    string    `json:"timestamp"`
+	TradeValue   FlexFloat `json:"trade_value"`
 }
 
+// PositionBookResponse is the typed response of the positionbook endpoint
 type PositionBookResponse struct {
-	Status string `json:"status"`
-	Data   []struct {
-		Symbol       string `json:"symbol"`
-		Exchange     string `json:"exchange"`
-		Product      string `json:"product"`
-		Quantity     string `json:"quantity"`
-		AveragePrice string `json:"average_price"`
-		LTP          string `json:"ltp"`
-		PnL          string `json:"pnl"`
-	} `json:"data"`
+	Status string     `json:"status"`
+	Data   []Position `json:"data"`
+}
+
+// Position is a single open or closed position
+type Position struct {
+	Symbol       string    `json:"symbol"`
+	Exchange     string    `json:"exchange"`
+	Product      string    `json:"product"`
+	Quantity     FlexInt   `json:"quantity"`
+	AveragePrice FlexFloat `json:"average_price"`
+	LTP          FlexFloat `json:"ltp"`
+	PnL          FlexFloat `json:"pnl"`
 }
 
+// HoldingsResponse is the typed response of the holdings endpoint
 type HoldingsResponse struct {
-	Status string `json:"status"`
-	Data   struct {
-		Holdings []struct {
-			Symbol     string  `json:"symbol"`
-			Exchange   string  `json:"exchange"`
-			Product    string  `json:"product"`
-			Quantity   int     `json:"quantity"`
-			PnL        float64 `json:"pnl"`
-			PnLPercent float64 `json:"pnlpercent"`
-		} `json:"holdings"`
-		Statistics struct {
-			TotalHoldingValue float64 `json:"totalholdingvalue"`
-			TotalInvValue     float64 `json:"totalinvvalue"`
-			TotalPnL          float64 `json:"totalprofitandloss"`
-			TotalPnLPercent   float64 `json:"totalpnlpercentage"`
-		} `json:"statistics"`
-	} `json:"data"`
+	Status string       `json:"status"`
+	Data   HoldingsData `json:"data"`
+}
+
+// HoldingsData holds the demat holdings and their statistics
+type HoldingsData struct {
+	Holdings   []Holding          `json:"holdings"`
+	Statistics HoldingsStatistics `json:"statistics"`
+}
+
+// Holding is a single demat holding
+type Holding struct {
+	Symbol     string    `json:"symbol"`
+	Exchange   string    `json:"exchange"`
+	Product    string    `json:"product"`
+	Quantity   FlexInt   `json:"quantity"`
+	PnL        FlexFloat `json:"pnl"`
+	PnLPercent FlexFloat `json:"pnlpercent"`
+}
+
+// HoldingsStatistics summarises the holdings
+type HoldingsStatistics struct {
+	TotalHoldingValue FlexFloat `json:"totalholdingvalue"`
+	TotalInvValue     FlexFloat `json:"totalinvvalue"`
+	TotalPnL          FlexFloat `json:"totalprofitandloss"`
+	TotalPnLPercent   FlexFloat `json:"totalpnlpercentage"`
 }
 
 func (c *Client) Funds() (map[string]interface{}, error) {
+	return c.FundsCtx(context.Background())
+}
+
+// FundsCtx is like Funds but honors ctx for cancellation and deadlines
+func (c *Client) FundsCtx(ctx context.Context) (map[string]interface{}, error) {
 	payload := map[string]interface{}{
 		"apikey": c.apiKey,
 	}
-	return c.makeRequest("POST", "funds", payload)
+	return c.makeRequest(ctx, "POST", "funds", payload)
 }
 
 func (c *Client) OrderBook() (map[string]interface{}, error) {
+	return c.OrderBookCtx(context.Background())
+}
+
+// OrderBookCtx is like OrderBook but honors ctx for cancellation and deadlines
+func (c *Client) OrderBookCtx(ctx context.Context) (map[string]interface{}, error) {
 	payload := map[string]interface{}{
 		"apikey": c.apiKey,
 	}
-	return c.makeRequest("POST", "orderbook", payload)
+	return c.makeRequest(ctx, "POST", "orderbook", payload)
 }
 
 func (c *Client) TradeBook() (map[string]interface{}, error) {
+	return c.TradeBookCtx(context.Background())
+}
+
+// TradeBookCtx is like TradeBook but honors ctx for cancellation and deadlines
+func (c *Client) TradeBookCtx(ctx context.Context) (map[string]interface{}, error) {
 	payload := map[string]interface{}{
 		"apikey": c.apiKey,
 	}
-	return c.makeRequest("POST", "tradebook", payload)
+	return c.makeRequest(ctx, "POST", "tradebook", payload)
 }
 
 func (c *Client) PositionBook() (map[string]interface{}, error) {
+	return c.PositionBookCtx(context.Background())
+}
+
+// PositionBookCtx is like PositionBook but honors ctx for cancellation and deadlines
+func (c *Client) PositionBookCtx(ctx context.Context) (map[string]interface{}, error) {
 	payload := map[string]interface{}{
 		"apikey": c.apiKey,
 	}
-	return c.makeRequest("POST", "positionbook", payload)
+	return c.makeRequest(ctx, "POST", "positionbook", payload)
 }
 
 func (c *Client) Holdings() (map[string]interface{}, error) {
+	return c.HoldingsCtx(context.Background())
+}
+
+// HoldingsCtx is like Holdings but honors ctx for cancellation and deadlines
+func (c *Client) HoldingsCtx(ctx context.Context) (map[string]interface{}, error) {
 	payload := map[string]interface{}{
 		"apikey": c.apiKey,
 	}
-	return c.makeRequest("POST", "holdings", payload)
-}
\ No newline at end of file
+	return c.makeRequest(ctx, "POST", "holdings", payload)
+}
+
+// FundsTyped is like FundsCtx but decodes the response into a FundsResponse
+func (c *Client) FundsTyped(ctx context.Context) (*FundsResponse, error) {
+	var resp FundsResponse
+	if err := c.decodeRequest(ctx, "POST", "funds", c.apiKeyPayload(), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// OrderBookTyped is like OrderBookCtx but decodes the response into an
+// OrderBookResponse
+func (c *Client) OrderBookTyped(ctx context.Context) (*OrderBookResponse, error) {
+	var resp OrderBookResponse
+	if err := c.decodeRequest(ctx, "POST", "orderbook", c.apiKeyPayload(), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// TradeBookTyped is like TradeBookCtx but decodes the response into a
+// TradeBookResponse
+func (c *Client) TradeBookTyped(ctx context.Context) (*TradeBookResponse, error) {
+	var resp TradeBookResponse
+	if err := c.decodeRequest(ctx, "POST", "tradebook", c.apiKeyPayload(), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// PositionBookTyped is like PositionBookCtx but decodes the response into a
+// PositionBookResponse
+func (c *Client) PositionBookTyped(ctx context.Context) (*PositionBookResponse, error) {
+	var resp PositionBookResponse
+	if err := c.decodeRequest(ctx, "POST", "positionbook", c.apiKeyPayload(), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// HoldingsTyped is like HoldingsCtx but decodes the response into a
+// HoldingsResponse
+func (c *Client) HoldingsTyped(ctx context.Context) (*HoldingsResponse, error) {
+	var resp HoldingsResponse
+	if err := c.decodeRequest(ctx, "POST", "holdings", c.apiKeyPayload(), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// apiKeyPayload returns a request body carrying only the API key
+func (c *Client) apiKeyPayload() map[string]interface{} {
+	return map[string]interface{}{
+		"apikey": c.apiKey,
+	}
+}
@@ -1,5 +1,7 @@
 package openalgo
 
+import "context"
+
 type AnalyzerStatusResponse struct {
 	Status string `json:"status"`
 	Data   struct {
@@ -24,16 +26,26 @@ type AnalyzerToggleResponse struct {
 }
 
 func (c *Client) AnalyzerStatus() (map[string]interface{}, error) {
+	return c.AnalyzerStatusCtx(context.Background())
+}
+
+// AnalyzerStatusCtx is like AnalyzerStatus but honors ctx for cancellation and deadlines
+func (c *Client) AnalyzerStatusCtx(ctx context.Context) (map[string]interface{}, error) {
 	payload := map[string]interface{}{
 		"apikey": c.apiKey,
 	}
-	return c.makeRequest("POST", "analyzer", payload)
+	return c.makeRequest(ctx, "POST", "analyzer", payload)
 }
 
 func (c *Client) AnalyzerToggle(mode bool) (map[string]interface{}, error) {
+	return c.AnalyzerToggleCtx(context.Background(), mode)
+}
+
+// AnalyzerToggleCtx is like AnalyzerToggle but honors ctx for cancellation and deadlines
+func (c *Client) AnalyzerToggleCtx(ctx context.Context, mode bool) (map[string]interface{}, error) {
 	payload := map[string]interface{}{
 		"apikey": c.apiKey,
 		"mode":   mode,
 	}
-	return c.makeRequest("POST", "analyzer/toggle", payload)
-}
\ No newline at end of file
+	return c.makeRequest(ctx, "POST", "analyzer/toggle", payload)
+}
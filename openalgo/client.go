@@ -2,6 +2,7 @@ package openalgo
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,124 +12,349 @@ import (
 	"github.com/gorilla/websocket"
 )
 
-// Client is the main OpenAlgo API client
+// Client is the main OpenAlgo API client. It is safe for concurrent use by
+// multiple goroutines.
 type Client struct {
 	apiKey    string
 	host      string
 	baseURL   string
 	wsURL     string
 	wsPort    int
+	userAgent string
+	retry     RetryPolicy
+	limiter   *rateLimiter
 	client    *http.Client
-	wsConn    *websocket.Conn
-	callbacks map[string]func(interface{})
+	reconnect ReconnectPolicy
+	heartbeat HeartbeatConfig
+	// authTimeout bounds the wait for the WebSocket authentication reply,
+	// ackTimeout the wait for subscription acknowledgements
+	authTimeout   time.Duration
+	ackTimeout    time.Duration
+	logger        Logger
+	requestHooks  []RequestHook
+	responseHooks []ResponseHook
+	metrics       MetricsCollector
+	// tracer is nil when tracing is disabled
+	tracer Tracer
+	// wsDialer and wsHeader are used to open WebSocket connections
+	wsDialer *websocket.Dialer
+	wsHeader http.Header
+	ws       wsState
 }
 
-// NewClient creates a new OpenAlgo API client
+// NewClient creates a new OpenAlgo API client.
+//
+// The optional arguments are interpreted by position and type: a string at
+// index 0 is the API version, a later string is the WebSocket URL and an int
+// is the WebSocket port. New code should prefer NewClientWithOptions.
 func NewClient(apiKey string, host string, optionalArgs ...interface{}) *Client {
-	version := "v1"
-	wsPort := 8765
-	var wsURL string
+	cfg := defaultClientConfig()
 
 	// Parse optional arguments
 	for i, arg := range optionalArgs {
 		switch v := arg.(type) {
 		case string:
 			if i == 0 && v != "" {
-				version = v
+				cfg.version = v
 			} else if i > 0 && v != "" {
-				wsURL = v
+				cfg.wsURL = v
 			}
 		case int:
-			wsPort = v
+			cfg.wsPort = v
 		}
 	}
 
+	return newClient(apiKey, host, cfg)
+}
+
+// NewClientWithOptions creates a new OpenAlgo API client configured by opts.
+// Unlike NewClient it validates its input and returns an error instead of
+// silently falling back to defaults.
+func NewClientWithOptions(apiKey, host string, opts ...Option) (*Client, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("API key is required")
+	}
+	if err := validateHost(host); err != nil {
+		return nil, err
+	}
+
+	cfg := defaultClientConfig()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	return newClient(apiKey, host, cfg), nil
+}
+
+// newClient builds a Client from an already parsed configuration
+func newClient(apiKey, host string, cfg *clientConfig) *Client {
+	httpClient := cfg.httpClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	if cfg.timeout > 0 || cfg.transport != nil || len(cfg.middlewares) > 0 {
+		// Copy so that a caller supplied client is never mutated
+		hc := *httpClient
+		if cfg.timeout > 0 {
+			hc.Timeout = cfg.timeout
+		}
+		if cfg.transport != nil {
+			hc.Transport = cfg.transport
+		}
+		if len(cfg.middlewares) > 0 {
+			base := hc.Transport
+			if base == nil {
+				base = http.DefaultTransport
+			}
+			hc.Transport = chainTransport(base, cfg.middlewares)
+		}
+		httpClient = &hc
+	}
+
 	c := &Client{
-		apiKey:    apiKey,
-		host:      host,
-		baseURL:   fmt.Sprintf("%s/api/%s/", host, version),
-		wsPort:    wsPort,
-		client:    &http.Client{Timeout: 30 * time.Second},
-		callbacks: make(map[string]func(interface{})),
+		apiKey:        apiKey,
+		host:          host,
+		baseURL:       fmt.Sprintf("%s/api/%s/", host, cfg.version),
+		wsPort:        cfg.wsPort,
+		userAgent:     cfg.userAgent,
+		retry:         cfg.retry,
+		reconnect:     cfg.reconnect,
+		heartbeat:     cfg.heartbeat,
+		authTimeout:   cfg.authTimeout,
+		ackTimeout:    cfg.ackTimeout,
+		logger:        cfg.logger,
+		wsDialer:      cfg.wsDialer,
+		wsHeader:      cfg.wsHeader,
+		requestHooks:  cfg.requestHooks,
+		responseHooks: cfg.responseHooks,
+		metrics:       cfg.metrics,
+		tracer:        cfg.tracer,
+		limiter:       newRateLimiter(cfg.rateLimits),
+		client:        httpClient,
 	}
+	c.ws.queue.notify = make(chan struct{}, 1)
 
 	// Set WebSocket URL
-	if wsURL != "" {
-		c.wsURL = wsURL
+	if cfg.wsURL != "" {
+		c.wsURL = cfg.wsURL
 	} else {
-		// Extract host without protocol for WebSocket
-		wsHost := host
-		if len(host) > 7 && host[:7] == "http://" {
-			wsHost = host[7:]
-		} else if len(host) > 8 && host[:8] == "https://" {
-			wsHost = host[8:]
-		}
-		// Remove port if present
-		for i, ch := range wsHost {
-			if ch == ':' || ch == '/' {
-				wsHost = wsHost[:i]
-				break
-			}
-		}
-		c.wsURL = fmt.Sprintf("ws://%s:%d", wsHost, wsPort)
+		c.wsURL = deriveWSURL(host, cfg.wsPort)
 	}
 
 	return c
 }
 
-// makeRequest performs an HTTP request to the OpenAlgo API
-func (c *Client) makeRequest(method, endpoint string, payload interface{}) (map[string]interface{}, error) {
-	url := fmt.Sprintf("%s%s", c.baseURL, endpoint)
+// deriveWSURL builds the default WebSocket URL from the REST host, using
+// wss:// for https:// hosts
+func deriveWSURL(host string, wsPort int) string {
+	// Extract host without protocol for WebSocket
+	scheme := "ws"
+	wsHost := host
+	if len(host) > 7 && host[:7] == "http://" {
+		wsHost = host[7:]
+	} else if len(host) > 8 && host[:8] == "https://" {
+		scheme = "wss"
+		wsHost = host[8:]
+	}
+	// Remove port if present
+	for i, ch := range wsHost {
+		if ch == ':' || ch == '/' {
+			wsHost = wsHost[:i]
+			break
+		}
+	}
+	return fmt.Sprintf("%s://%s:%d", scheme, wsHost, wsPort)
+}
 
-	var req *http.Request
-	var err error
+// makeRequest performs an HTTP request to the OpenAlgo API, retrying it
+// according to the client's retry policy
+func (c *Client) makeRequest(ctx context.Context, method, endpoint string, payload interface{}) (map[string]interface{}, error) {
+	result, _, err := c.makeRawRequest(ctx, method, endpoint, payload)
+	return result, err
+}
+
+// decodeRequest performs an HTTP request like makeRequest and decodes the
+// response body into out
+func (c *Client) decodeRequest(ctx context.Context, method, endpoint string, payload interface{}, out interface{}) error {
+	_, body, err := c.makeRawRequest(ctx, method, endpoint, payload)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", endpoint, err)
+	}
+	return nil
+}
+
+// makeRawRequest performs an HTTP request and returns both the decoded
+// response and the raw body
+func (c *Client) makeRawRequest(ctx context.Context, method, endpoint string, payload interface{}) (result map[string]interface{}, body []byte, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	url := fmt.Sprintf("%s%s", c.baseURL, endpoint)
 
+	var jsonData []byte
 	if payload != nil {
-		jsonData, err := json.Marshal(payload)
+		var err error
+		jsonData, err = json.Marshal(payload)
 		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+			return nil, nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		req, err = http.NewRequest(method, url, bytes.NewBuffer(jsonData))
-		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	maxAttempts := 1
+	if c.retry.appliesTo(endpoint) {
+		maxAttempts = c.retry.MaxAttempts
+	}
+
+	var sanitized map[string]interface{}
+	if c.hasHooks() || c.tracer != nil {
+		sanitized = sanitizePayload(jsonData)
+	}
+
+	ctx, span := c.startSpan(ctx, "openalgo."+endpoint, SpanConfig{Attributes: requestAttributes(method, endpoint, sanitized)})
+	var (
+		attempts int
+		last     response
+	)
+	defer func() {
+		endRequestSpan(span, attempts, last, err)
+	}()
+
+	for attempt := 1; ; attempt++ {
+		if err := c.limiter.wait(ctx, endpoint); err != nil {
+			return nil, nil, fmt.Errorf("request failed: %w", err)
 		}
-	} else {
-		req, err = http.NewRequest(method, url, nil)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
+
+		for _, hook := range c.requestHooks {
+			hook.OnRequest(ctx, RequestEvent{Endpoint: endpoint, Method: method, Attempt: attempt, Payload: sanitized})
+		}
+		start := time.Now()
+		resp, err := c.doRequest(ctx, method, url, endpoint, jsonData)
+		latency := time.Since(start)
+		attempts, last = attempt, resp
+		c.metrics.ObserveRequest(endpoint, latency, err)
+		for _, hook := range c.responseHooks {
+			hook.OnResponse(ctx, ResponseEvent{
+				Endpoint:   endpoint,
+				Method:     method,
+				Attempt:    attempt,
+				Payload:    sanitized,
+				Latency:    latency,
+				HTTPStatus: resp.status,
+				Result:     resp.result,
+				Err:        err,
+			})
+		}
+
+		if err == nil {
+			c.logger.Debug("request completed", "endpoint", endpoint, "attempt", attempt, "latency", latency)
+			return resp.result, resp.body, nil
+		}
+		if attempt >= maxAttempts || !c.retry.isRetryable(ctx, err) {
+			c.logger.Warn("request failed", "endpoint", endpoint, "attempt", attempt, "latency", latency, "error", err)
+			return nil, nil, err
+		}
+
+		backoff, ok := c.retry.backoff(attempt, resp.retryAfter)
+		if !ok {
+			c.logger.Warn("request failed", "endpoint", endpoint, "attempt", attempt, "latency", latency, "retry_after", resp.retryAfter, "error", err)
+			return nil, nil, err
+		}
+		c.logger.Info("retrying request", "endpoint", endpoint, "attempt", attempt, "latency", latency, "backoff", backoff, "error", err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, nil, fmt.Errorf("request failed: %w", ctx.Err())
+		case <-timer.C:
 		}
 	}
+}
+
+// response is the outcome of a single HTTP round trip
+type response struct {
+	// result is the decoded body, set whenever the body is JSON
+	result map[string]interface{}
+	body   []byte
+	status int
+	// retryAfter is the server's Retry-After hint, zero if none was sent
+	retryAfter time.Duration
+}
+
+// doRequest performs a single HTTP round trip and returns the decoded and raw
+// response. The response is filled as far as the round trip got, also when
+// an error is returned.
+func (c *Client) doRequest(ctx context.Context, method, url, endpoint string, jsonData []byte) (response, error) {
+	var r response
+	var body io.Reader
+	if jsonData != nil {
+		body = bytes.NewReader(jsonData)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return r, fmt.Errorf("failed to create request: %w", err)
+	}
 
 	req.Header.Set("Content-Type", "application/json")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if c.tracer != nil {
+		c.tracer.Inject(ctx, req.Header)
+	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return r, &transportError{err: err}
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	r.status = resp.StatusCode
+	r.retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+
+	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return r, &transportError{err: fmt.Errorf("failed to read response body: %w", err)}
 	}
+	r.body = respBody
 
 	// Check if response is JSON
 	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		// If response is not JSON, include the actual response in error for debugging
-		if len(body) > 200 {
-			return nil, fmt.Errorf("failed to unmarshal response: %w (response: %s...)", err, string(body[:200]))
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		if resp.StatusCode >= http.StatusBadRequest {
+			return r, newAPIError(endpoint, resp.StatusCode, "", truncateBody(respBody), respBody)
 		}
-		return nil, fmt.Errorf("failed to unmarshal response: %w (response: %s)", err, string(body))
+		// If response is not JSON, include the actual response in error for debugging
+		r.retryAfter = 0
+		return r, fmt.Errorf("failed to unmarshal response: %w (response: %s)", err, truncateBody(respBody))
 	}
+	r.result = result
 
 	// Check if API returned an error
-	if status, ok := result["status"].(string); ok && status == "error" {
-		if msg, ok := result["message"].(string); ok {
-			return nil, fmt.Errorf("API error: %s", msg)
+	status, _ := result["status"].(string)
+	if status == "error" || resp.StatusCode >= http.StatusBadRequest {
+		msg, _ := result["message"].(string)
+		if msg == "" && status == "error" {
+			msg = fmt.Sprintf("%v", result)
 		}
-		return nil, fmt.Errorf("API error: %v", result)
+		return r, newAPIError(endpoint, resp.StatusCode, status, msg, respBody)
 	}
 
-	return result, nil
-}
\ No newline at end of file
+	r.retryAfter = 0
+	return r, nil
+}
+
+// truncateBody shortens a response body for inclusion in error messages
+func truncateBody(body []byte) string {
+	if len(body) > 200 {
+		return string(body[:200]) + "..."
+	}
+	return string(body)
+}
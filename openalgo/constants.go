@@ -0,0 +1,47 @@
+package openalgo
+
+// Action is the side of an order
+type Action string
+
+// Order actions
+const (
+	ActionBuy  Action = "BUY"
+	ActionSell Action = "SELL"
+)
+
+// Exchange is an exchange segment code understood by OpenAlgo
+type Exchange string
+
+// Exchange segments
+const (
+	ExchangeNSE      Exchange = "NSE"
+	ExchangeBSE      Exchange = "BSE"
+	ExchangeNFO      Exchange = "NFO"
+	ExchangeBFO      Exchange = "BFO"
+	ExchangeCDS      Exchange = "CDS"
+	ExchangeBCD      Exchange = "BCD"
+	ExchangeMCX      Exchange = "MCX"
+	ExchangeNSEIndex Exchange = "NSE_INDEX"
+	ExchangeBSEIndex Exchange = "BSE_INDEX"
+)
+
+// PriceType is the order type
+type PriceType string
+
+// Price types
+const (
+	PriceTypeMarket PriceType = "MARKET"
+	PriceTypeLimit  PriceType = "LIMIT"
+	PriceTypeSL     PriceType = "SL"
+	PriceTypeSLM    PriceType = "SL-M"
+)
+
+// Product is the product type an order is placed under
+type Product string
+
+// Products
+const (
+	ProductMIS  Product = "MIS"
+	ProductCNC  Product = "CNC"
+	ProductNRML Product = "NRML"
+)
@@ -0,0 +1,292 @@
+package openalgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Quantity is a whole number of shares, lots or contracts
+type Quantity int64
+
+// ParseQuantity converts v to a Quantity. It accepts every integer kind,
+// strings holding an integer and floating point values without a fractional
+// part; fractional values and any other type are rejected.
+func ParseQuantity(v interface{}) (Quantity, error) {
+	return parseQuantity("quantity", v)
+}
+
+// quantityParam converts the quantity-like order parameter name to the
+// string sent to the API
+func quantityParam(name string, v interface{}) (string, error) {
+	q, err := parseQuantity(name, v)
+	if err != nil {
+		return "", err
+	}
+	return q.String(), nil
+}
+
+// parseQuantity implements ParseQuantity, naming the offending parameter in
+// errors
+func parseQuantity(name string, v interface{}) (Quantity, error) {
+	switch q := v.(type) {
+	case Quantity:
+		return q, nil
+	case int:
+		return Quantity(q), nil
+	case int8:
+		return Quantity(q), nil
+	case int16:
+		return Quantity(q), nil
+	case int32:
+		return Quantity(q), nil
+	case int64:
+		return Quantity(q), nil
+	case uint:
+		return uintQuantity(name, uint64(q))
+	case uint8:
+		return Quantity(q), nil
+	case uint16:
+		return Quantity(q), nil
+	case uint32:
+		return Quantity(q), nil
+	case uint64:
+		return uintQuantity(name, q)
+	case float32:
+		return floatQuantity(name, float64(q))
+	case float64:
+		return floatQuantity(name, q)
+	case string:
+		s := strings.TrimSpace(q)
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return Quantity(n), nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s %q is not a number", ErrInvalidParameter, name, q)
+		}
+		return floatQuantity(name, f)
+	case nil:
+		return 0, fmt.Errorf("%w: %s is required", ErrInvalidParameter, name)
+	}
+	return 0, fmt.Errorf("%w: unsupported %s type %T", ErrInvalidParameter, name, v)
+}
+
+func uintQuantity(name string, v uint64) (Quantity, error) {
+	if v > math.MaxInt64 {
+		return 0, fmt.Errorf("%w: %s %d is too large", ErrInvalidParameter, name, v)
+	}
+	return Quantity(v), nil
+}
+
+func floatQuantity(name string, v float64) (Quantity, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) || v != math.Trunc(v) {
+		return 0, fmt.Errorf("%w: %s %v is not a whole number", ErrInvalidParameter, name, v)
+	}
+	if v >= math.MaxInt64 || v < math.MinInt64 {
+		return 0, fmt.Errorf("%w: %s %v is out of range", ErrInvalidParameter, name, v)
+	}
+	return Quantity(v), nil
+}
+
+// String returns q in the format expected by the API
+func (q Quantity) String() string {
+	return strconv.FormatInt(int64(q), 10)
+}
+
+// maxPriceScale is the number of decimal places kept by a Price
+const maxPriceScale = 8
+
+// Price is an exact decimal price. It is stored as an integer number of
+// units at a decimal scale so that it is always sent as plain decimal text,
+// never in scientific notation or with binary floating point noise. The zero
+// value is a price of 0.
+type Price struct {
+	units int64
+	scale uint8
+}
+
+// NewPrice converts f to a Price, rounding it to 8 decimal places
+func NewPrice(f float64) Price {
+	p, err := ParsePrice(strconv.FormatFloat(f, 'f', maxPriceScale, 64))
+	if err != nil {
+		// Only reachable for NaN, infinities and values beyond int64 range
+		return Price{}
+	}
+	return p
+}
+
+// ParsePrice parses a decimal string such as "1520.05" into a Price
+func ParsePrice(s string) (Price, error) {
+	s = strings.TrimSpace(s)
+	digits := s
+	neg := false
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		neg = digits[0] == '-'
+		digits = digits[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(digits, ".")
+	fracPart = strings.TrimRight(fracPart, "0")
+	if intPart == "" && fracPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return Price{}, fmt.Errorf("%w: invalid price %q", ErrInvalidParameter, s)
+	}
+	if len(fracPart) > maxPriceScale {
+		return Price{}, fmt.Errorf("%w: price %q has more than %d decimal places", ErrInvalidParameter, s, maxPriceScale)
+	}
+
+	units, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if err != nil {
+		return Price{}, fmt.Errorf("%w: price %q is out of range", ErrInvalidParameter, s)
+	}
+	if neg {
+		units = -units
+	}
+	return Price{units: units, scale: uint8(len(fracPart))}, nil
+}
+
+// ParsePriceValue converts v to a Price. It accepts Price, integer and
+// floating point values and decimal strings.
+func ParsePriceValue(v interface{}) (Price, error) {
+	switch p := v.(type) {
+	case Price:
+		return p, nil
+	case float64:
+		return floatPrice(p)
+	case float32:
+		return ParsePrice(strconv.FormatFloat(float64(p), 'f', -1, 32))
+	case string:
+		return ParsePrice(p)
+	}
+	q, err := ParseQuantity(v)
+	if err != nil {
+		return Price{}, fmt.Errorf("%w: unsupported price type %T", ErrInvalidParameter, v)
+	}
+	return Price{units: int64(q)}, nil
+}
+
+func floatPrice(f float64) (Price, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Price{}, fmt.Errorf("%w: invalid price %v", ErrInvalidParameter, f)
+	}
+	return ParsePrice(strconv.FormatFloat(f, 'f', maxPriceScale, 64))
+}
+
+func isDigits(s string) bool {
+	for _, ch := range s {
+		if ch < '0' || ch > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns p as plain decimal text without trailing zeros
+func (p Price) String() string {
+	units := p.units
+	sign := ""
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+	s := strconv.FormatInt(units, 10)
+	if p.scale == 0 {
+		return sign + s
+	}
+	scale := int(p.scale)
+	if len(s) <= scale {
+		s = strings.Repeat("0", scale-len(s)+1) + s
+	}
+	return sign + s[:len(s)-scale] + "." + s[len(s)-scale:]
+}
+
+// Float64 returns the nearest float64 to p
+func (p Price) Float64() float64 {
+	return float64(p.units) / math.Pow10(int(p.scale))
+}
+
+// IsZero reports whether p is zero
+func (p Price) IsZero() bool {
+	return p.units == 0
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of p
+func (p Price) Sign() int {
+	switch {
+	case p.units < 0:
+		return -1
+	case p.units > 0:
+		return 1
+	}
+	return 0
+}
+
+// Cmp compares p and q and returns -1, 0 or +1
+func (p Price) Cmp(q Price) int {
+	if p.scale == q.scale {
+		switch {
+		case p.units < q.units:
+			return -1
+		case p.units > q.units:
+			return 1
+		}
+		return 0
+	}
+	// Bring both to the larger scale in big integers, as scaling up may
+	// overflow int64
+	a, b := big.NewInt(p.units), big.NewInt(q.units)
+	if p.scale < q.scale {
+		a.Mul(a, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(q.scale-p.scale)), nil))
+	} else {
+		b.Mul(b, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(p.scale-q.scale)), nil))
+	}
+	return a.Cmp(b)
+}
+
+// MarshalJSON encodes p as a decimal string, the format used by the API
+func (p Price) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+// UnmarshalJSON decodes p from a JSON number or decimal string
+func (p *Price) UnmarshalJSON(data []byte) error {
+	s, ok, err := flexNumberText(data)
+	if err != nil || !ok {
+		*p = Price{}
+		return err
+	}
+	v, err := ParsePrice(s)
+	if err != nil {
+		return err
+	}
+	*p = v
+	return nil
+}
+
+// formatParam converts an optional order parameter to the string sent to the
+// API. Floating point values are written as exact decimals.
+func formatParam(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case Price:
+		return v.String()
+	case Quantity:
+		return v.String()
+	case float32, float64:
+		if p, err := ParsePriceValue(v); err == nil {
+			return p.String()
+		}
+		if f, ok := v.(float64); ok {
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		if q, err := ParseQuantity(v); err == nil {
+			return q.String()
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
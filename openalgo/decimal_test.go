@@ -0,0 +1,201 @@
+package openalgo
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    Quantity
+		wantErr bool
+	}{
+		{name: "int", value: 10, want: 10},
+		{name: "int64", value: int64(math.MaxInt64), want: math.MaxInt64},
+		{name: "uint32", value: uint32(math.MaxUint32), want: math.MaxUint32},
+		{name: "uint64 in range", value: uint64(math.MaxInt64), want: math.MaxInt64},
+		{name: "whole float", value: 25.0, want: 25},
+		{name: "numeric string", value: " 75 ", want: 75},
+		{name: "whole float string", value: "75.0", want: 75},
+		{name: "negative", value: -3, want: -3},
+		{name: "fractional float", value: 2.6, wantErr: true},
+		{name: "fractional float32", value: float32(2.5), wantErr: true},
+		{name: "fractional string", value: "2.6", wantErr: true},
+		{name: "uint overflow", value: uint(math.MaxUint), wantErr: true},
+		{name: "uint64 overflow", value: uint64(math.MaxInt64) + 1, wantErr: true},
+		{name: "string beyond int64", value: "9223372036854775808", wantErr: true},
+		{name: "float beyond int64", value: 1e19, wantErr: true},
+		{name: "NaN", value: math.NaN(), wantErr: true},
+		{name: "infinity", value: math.Inf(1), wantErr: true},
+		{name: "not a number", value: "ten", wantErr: true},
+		{name: "nil", value: nil, wantErr: true},
+		{name: "unsupported type", value: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseQuantity("quantity", tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidParameter) {
+					t.Fatalf("parseQuantity(%v) = %v, %v, want an error matching ErrInvalidParameter", tt.value, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseQuantity(%v): %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseQuantity(%v) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "1520.050", want: "1520.05"},
+		{in: "1520.05", want: "1520.05"},
+		{in: "1520", want: "1520"},
+		{in: "1520.", want: "1520"},
+		{in: ".05", want: "0.05"},
+		{in: "0.00000001", want: "0.00000001"},
+		{in: "-12.50", want: "-12.5"},
+		{in: "-0.05", want: "-0.05"},
+		{in: "+3.1", want: "3.1"},
+		{in: "123456789012.5", want: "123456789012.5"},
+		{in: "0.000000001", wantErr: true},
+		{in: "1e3", wantErr: true},
+		{in: "1,520", wantErr: true},
+		{in: "abc", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "-", wantErr: true},
+		{in: "99999999999999999999", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParsePrice(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidParameter) {
+					t.Fatalf("ParsePrice(%q) = %v, %v, want an error matching ErrInvalidParameter", tt.in, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePrice(%q): %v", tt.in, err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("ParsePrice(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPrice(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want string
+	}{
+		{in: 1520.05, want: "1520.05"},
+		{in: 0.1 + 0.2, want: "0.3"},
+		{in: 100, want: "100"},
+		{in: 1e9, want: "1000000000"},
+		{in: 12345678.9, want: "12345678.9"},
+		{in: 1e-7, want: "0.0000001"},
+		{in: -0.05, want: "-0.05"},
+		{in: -1520.5, want: "-1520.5"},
+		{in: 0, want: "0"},
+		{in: math.NaN(), want: "0"},
+		{in: math.Inf(1), want: "0"},
+	}
+
+	for _, tt := range tests {
+		got := NewPrice(tt.in).String()
+		if got != tt.want {
+			t.Errorf("NewPrice(%v) = %s, want %s", tt.in, got, tt.want)
+		}
+		if strings.ContainsAny(got, "eE") {
+			t.Errorf("NewPrice(%v) = %s is in scientific notation", tt.in, got)
+		}
+	}
+}
+
+func TestPriceCmp(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "1.5", b: "1.50", want: 0},
+		{a: "1.5", b: "1.05", want: 1},
+		{a: "1.05", b: "1.5", want: -1},
+		{a: "2", b: "1.99999999", want: 1},
+		{a: "1.99999999", b: "2", want: -1},
+		{a: "-1.5", b: "-1.05", want: -1},
+		{a: "-1", b: "0.00000001", want: -1},
+		{a: "0", b: "0.00", want: 0},
+		{a: "92233720368", b: "0.00000001", want: 1},
+		{a: "0.00000001", b: "92233720368", want: -1},
+		{a: "-92233720368", b: "0.5", want: -1},
+		{a: "92233720368", b: "92233720367.99999999", want: 1},
+		{a: "1000000000000", b: "0.00000001", want: 1},
+		{a: "93000000000", b: "0.00000001", want: 1},
+		{a: "93000000000", b: "92999999999.5", want: 1},
+		{a: "-1000000000000", b: "0.00000001", want: -1},
+		{a: "0.00000001", b: "-1000000000000", want: 1},
+	}
+
+	for _, tt := range tests {
+		a, err := ParsePrice(tt.a)
+		if err != nil {
+			t.Fatalf("ParsePrice(%q): %v", tt.a, err)
+		}
+		b, err := ParsePrice(tt.b)
+		if err != nil {
+			t.Fatalf("ParsePrice(%q): %v", tt.b, err)
+		}
+		if got := a.Cmp(b); got != tt.want {
+			t.Errorf("%s.Cmp(%s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestFormatParam(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "string", value: "100.50", want: "100.50"},
+		{name: "float", value: 1520.05, want: "1520.05"},
+		{name: "float noise", value: 0.1 + 0.2, want: "0.3"},
+		{name: "large float", value: 1e9, want: "1000000000"},
+		{name: "larger float", value: 2.5e13, want: "25000000000000"},
+		{name: "float32", value: float32(99.95), want: "99.95"},
+		{name: "negative float", value: -12.5, want: "-12.5"},
+		{name: "int", value: 25, want: "25"},
+		{name: "uint64", value: uint64(7), want: "7"},
+		{name: "price", value: NewPrice(820.5), want: "820.5"},
+		{name: "quantity", value: Quantity(3), want: "3"},
+		{name: "bool", value: true, want: "true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatParam(tt.value)
+			if got != tt.want {
+				t.Errorf("formatParam(%v) = %q, want %q", tt.value, got, tt.want)
+			}
+			if strings.ContainsAny(got, "eE") && tt.name != "bool" {
+				t.Errorf("formatParam(%v) = %q is in scientific notation", tt.value, got)
+			}
+		})
+	}
+}
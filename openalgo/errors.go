@@ -0,0 +1,131 @@
+package openalgo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors classifying failures reported by the OpenAlgo server.
+// Use errors.Is to test an error returned by the client against them.
+var (
+	// ErrInvalidAPIKey is reported when the server rejects the API key
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	// ErrRateLimited is reported when the server throttles the request
+	ErrRateLimited = errors.New("rate limited")
+	// ErrOrderRejected is reported when an order endpoint refuses the request
+	ErrOrderRejected = errors.New("order rejected")
+	// ErrBrokerUnavailable is reported when the server or the broker behind it
+	// cannot serve the request
+	ErrBrokerUnavailable = errors.New("broker unavailable")
+	// ErrNotConnected is returned by WebSocket calls made without a connection
+	ErrNotConnected = errors.New("not connected to WebSocket server")
+	// ErrInvalidParameter is returned when a request argument is missing or
+	// malformed before anything is sent to the server
+	ErrInvalidParameter = errors.New("invalid parameter")
+	// ErrNoAcknowledgement is reported when the server does not answer a
+	// WebSocket subscription within the ack timeout
+	ErrNoAcknowledgement = errors.New("no acknowledgement from server")
+	// ErrDepthUnavailable is reported when the server provides fewer depth
+	// levels than requested
+	ErrDepthUnavailable = errors.New("depth levels unavailable")
+)
+
+// orderEndpoints lists the endpoints whose failures are reported as
+// ErrOrderRejected
+var orderEndpoints = map[string]bool{
+	"placeorder":      true,
+	"placesmartorder": true,
+	"basketorder":     true,
+	"splitorder":      true,
+	"modifyorder":     true,
+	"cancelorder":     true,
+	"cancelallorder":  true,
+	"closeposition":   true,
+}
+
+// APIError is returned when the OpenAlgo server answers with an error,
+// either through an error HTTP status or a "status": "error" payload
+type APIError struct {
+	// Endpoint is the API endpoint, e.g. "placeorder", or "websocket" for
+	// errors reported on the streaming connection
+	Endpoint string
+	// HTTPStatus is the HTTP status code, zero for WebSocket errors
+	HTTPStatus int
+	// Status is the "status" field of the response, usually "error"
+	Status string
+	// Message is the server supplied error message
+	Message string
+	// Payload is the raw response body
+	Payload []byte
+
+	kind error
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.HTTPStatus)
+	}
+	if e.HTTPStatus != 0 {
+		return fmt.Sprintf("API error: %s (endpoint %s, HTTP %d)", msg, e.Endpoint, e.HTTPStatus)
+	}
+	return fmt.Sprintf("API error: %s (endpoint %s)", msg, e.Endpoint)
+}
+
+// Unwrap returns the sentinel error classifying e, if any
+func (e *APIError) Unwrap() error {
+	return e.kind
+}
+
+// newAPIError builds an APIError and classifies it against the sentinel errors
+func newAPIError(endpoint string, httpStatus int, status, message string, payload []byte) *APIError {
+	return &APIError{
+		Endpoint:   endpoint,
+		HTTPStatus: httpStatus,
+		Status:     status,
+		Message:    message,
+		Payload:    payload,
+		kind:       classifyError(endpoint, httpStatus, message),
+	}
+}
+
+// classifyError maps an error response to one of the sentinel errors
+func classifyError(endpoint string, httpStatus int, message string) error {
+	msg := strings.ToLower(message)
+
+	switch {
+	case httpStatus == http.StatusUnauthorized || httpStatus == http.StatusForbidden,
+		isKeyMessage(msg):
+		return ErrInvalidAPIKey
+	case httpStatus == http.StatusTooManyRequests, strings.Contains(msg, "rate limit"):
+		return ErrRateLimited
+	case httpStatus >= http.StatusInternalServerError,
+		strings.Contains(msg, "broker") && (strings.Contains(msg, "unavailable") ||
+			strings.Contains(msg, "not connected") || strings.Contains(msg, "down")):
+		return ErrBrokerUnavailable
+	case orderEndpoints[endpoint]:
+		return ErrOrderRejected
+	}
+	return nil
+}
+
+// keyProblems are the words that, next to a mention of the API key, make an
+// error message report a bad key
+var keyProblems = []string{"invalid", "expired", "revoked", "unknown", "not found", "incorrect"}
+
+// isKeyMessage reports whether the lower-case error message msg blames the
+// API key, e.g. "Invalid OpenAlgo apikey" or "INVALID_API_KEY"
+func isKeyMessage(msg string) bool {
+	if !strings.Contains(msg, "api key") && !strings.Contains(msg, "apikey") && !strings.Contains(msg, "api_key") {
+		return false
+	}
+	for _, problem := range keyProblems {
+		if strings.Contains(msg, problem) {
+			return true
+		}
+	}
+	return false
+}
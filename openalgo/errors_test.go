@@ -0,0 +1,47 @@
+package openalgo
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name       string
+		endpoint   string
+		httpStatus int
+		message    string
+		want       error
+	}{
+		{name: "unauthorized", endpoint: "funds", httpStatus: http.StatusUnauthorized, want: ErrInvalidAPIKey},
+		{name: "forbidden", endpoint: "funds", httpStatus: http.StatusForbidden, want: ErrInvalidAPIKey},
+		{name: "invalid api key", endpoint: "funds", httpStatus: http.StatusBadRequest, message: "Invalid API key", want: ErrInvalidAPIKey},
+		{name: "invalid openalgo apikey", endpoint: "placeorder", message: "Invalid openalgo apikey", want: ErrInvalidAPIKey},
+		{name: "auth invalid key", endpoint: "authenticate", message: "Invalid API key", want: ErrInvalidAPIKey},
+		{name: "auth key code", endpoint: "authenticate", message: "INVALID_API_KEY", want: ErrInvalidAPIKey},
+		{name: "auth expired key", endpoint: "authenticate", message: "API key expired", want: ErrInvalidAPIKey},
+		{name: "auth connection limit", endpoint: "authenticate", message: "Maximum connections reached"},
+		{name: "auth unknown message", endpoint: "authenticate", message: "something went wrong"},
+		{name: "auth rate limit", endpoint: "authenticate", message: "Rate limit exceeded", want: ErrRateLimited},
+		{name: "auth broker down", endpoint: "authenticate", message: "Broker is down", want: ErrBrokerUnavailable},
+		{name: "rate limited", endpoint: "quotes", httpStatus: http.StatusTooManyRequests, want: ErrRateLimited},
+		{name: "server error", endpoint: "quotes", httpStatus: http.StatusBadGateway, want: ErrBrokerUnavailable},
+		{name: "order rejected", endpoint: "placeorder", httpStatus: http.StatusBadRequest, message: "Insufficient funds", want: ErrOrderRejected},
+		{name: "api key mentioned", endpoint: "funds", httpStatus: http.StatusBadRequest, message: "apikey is required"},
+		{name: "unclassified", endpoint: "quotes", httpStatus: http.StatusBadRequest, message: "symbol not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifyError(tt.endpoint, tt.httpStatus, tt.message)
+			if got != tt.want {
+				t.Errorf("classifyError(%q, %d, %q) = %v, want %v", tt.endpoint, tt.httpStatus, tt.message, got, tt.want)
+			}
+			err := newAPIError(tt.endpoint, tt.httpStatus, "error", tt.message, nil)
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("newAPIError(...) = %v, want an error matching %v", err, tt.want)
+			}
+		})
+	}
+}
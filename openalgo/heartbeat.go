@@ -0,0 +1,154 @@
+package openalgo
+
+import (
+	"fmt"
+	"time"
+)
+
+// HeartbeatConfig controls how the WebSocket connection is kept alive and
+// how silent connections and instruments are detected
+type HeartbeatConfig struct {
+	// PingInterval is the interval between ping frames sent to the server,
+	// 0 disables pings
+	PingInterval time.Duration
+	// PongWait is how long the connection may stay silent, with neither a
+	// pong nor a message received, before it is treated as lost and
+	// reconnected. 0 disables the read deadline.
+	PongWait time.Duration
+	// StaleAfter is how long a subscribed instrument may go without a tick
+	// during market hours before the OnStale handler is called, 0 disables
+	// stale detection
+	StaleAfter time.Duration
+	// StaleCheckInterval is how often subscriptions are checked for
+	// staleness, StaleAfter/2 when 0
+	StaleCheckInterval time.Duration
+	// MarketHours reports whether ticks are expected at the given time.
+	// Instruments are never reported stale outside market hours. nil means
+	// always.
+	MarketHours func(time.Time) bool
+}
+
+// DefaultHeartbeatConfig returns the configuration used when none is set:
+// pings every 20s, a 60s read deadline and instruments reported stale after
+// 30s without a tick during NSE market hours
+func DefaultHeartbeatConfig() HeartbeatConfig {
+	return HeartbeatConfig{
+		PingInterval: 20 * time.Second,
+		PongWait:     60 * time.Second,
+		StaleAfter:   30 * time.Second,
+		MarketHours:  NSEMarketHours,
+	}
+}
+
+func (h HeartbeatConfig) validate() error {
+	if h.PingInterval < 0 || h.PongWait < 0 || h.StaleAfter < 0 || h.StaleCheckInterval < 0 {
+		return fmt.Errorf("heartbeat intervals must not be negative")
+	}
+	if h.PingInterval > 0 && h.PongWait > 0 && h.PingInterval >= h.PongWait {
+		return fmt.Errorf("heartbeat ping interval %v must be shorter than pong wait %v", h.PingInterval, h.PongWait)
+	}
+	return nil
+}
+
+func (h HeartbeatConfig) checkInterval() time.Duration {
+	if h.StaleCheckInterval > 0 {
+		return h.StaleCheckInterval
+	}
+	return h.StaleAfter / 2
+}
+
+// NSEMarketHours reports whether t falls within the NSE equity session,
+// 09:15 to 15:30 IST Monday to Friday. Exchange holidays are not taken into
+// account.
+func NSEMarketHours(t time.Time) bool {
+	t = t.In(IST)
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	minutes := t.Hour()*60 + t.Minute()
+	return minutes >= 9*60+15 && minutes < 15*60+30
+}
+
+// OnStale registers fn to be called when a subscribed instrument has not
+// received a tick for HeartbeatConfig.StaleAfter during market hours.
+// lastTickAge is measured from the last tick, or from the subscription when
+// none has arrived. fn is called once per stale period; the next tick of the
+// instrument re-arms it.
+func (c *Client) OnStale(fn func(instrument Instrument, lastTickAge time.Duration)) {
+	c.ws.mu.Lock()
+	defer c.ws.mu.Unlock()
+	c.ws.onStale = fn
+}
+
+// staleWatchdog periodically reports stale subscriptions until closed is
+// closed by Disconnect
+func (c *Client) staleWatchdog(closed chan struct{}) {
+	hb := c.heartbeat
+	ticker := time.NewTicker(hb.checkInterval())
+	defer ticker.Stop()
+
+	// resumed is when market hours last began, so that ticks from the
+	// previous session do not make every instrument stale at the open
+	var resumed time.Time
+	open := false
+	for {
+		select {
+		case <-closed:
+			return
+		case now := <-ticker.C:
+			if hb.MarketHours != nil && !hb.MarketHours(now) {
+				open = false
+				continue
+			}
+			if !open {
+				open = true
+				resumed = now
+			}
+			c.checkStale(now, resumed)
+		}
+	}
+}
+
+// checkStale calls the OnStale handler for every instrument whose last tick
+// is older than StaleAfter and that has not been reported yet
+func (c *Client) checkStale(now, resumed time.Time) {
+	type staleInstrument struct {
+		instrument Instrument
+		age        time.Duration
+	}
+
+	c.ws.mu.Lock()
+	onStale := c.ws.onStale
+	if onStale == nil || c.ws.conn == nil {
+		c.ws.mu.Unlock()
+		return
+	}
+	var stale []staleInstrument
+	seen := make(map[Instrument]bool)
+	for key, entry := range c.ws.subscriptions {
+		since := entry.lastTick
+		if since.Before(c.ws.connectedAt) {
+			since = c.ws.connectedAt
+		}
+		if since.Before(resumed) {
+			since = resumed
+		}
+		age := now.Sub(since)
+		if entry.stale || age < c.heartbeat.StaleAfter {
+			continue
+		}
+		entry.stale = true
+
+		// An instrument subscribed in several modes is reported once
+		instrument := Instrument{Exchange: key.exchange, Symbol: key.symbol}
+		if !seen[instrument] {
+			seen[instrument] = true
+			stale = append(stale, staleInstrument{instrument: instrument, age: now.Sub(entry.lastTick)})
+		}
+	}
+	c.ws.mu.Unlock()
+
+	for _, s := range stale {
+		onStale(s.instrument, s.age)
+	}
+}
@@ -0,0 +1,109 @@
+package openalgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// redacted replaces the API key in payloads handed to hooks
+const redacted = "[REDACTED]"
+
+// RequestEvent describes a REST request about to be sent
+type RequestEvent struct {
+	Endpoint string
+	Method   string
+	// Attempt is 1 for the first attempt and grows with every retry
+	Attempt int
+	// Payload is a copy of the request body with the API key redacted.
+	// Numbers are json.Number values, so they keep their exact text.
+	Payload map[string]interface{}
+}
+
+// ResponseEvent describes the outcome of a REST request
+type ResponseEvent struct {
+	Endpoint string
+	Method   string
+	Attempt  int
+	// Payload is the same redacted copy passed to OnRequest
+	Payload map[string]interface{}
+	Latency time.Duration
+	// HTTPStatus is 0 when no response was received
+	HTTPStatus int
+	// Result is the decoded response body, also set for error responses
+	// when the body is JSON
+	Result map[string]interface{}
+	// Err is the error returned for this attempt, nil on success
+	Err error
+}
+
+// RequestHook is called before every REST request is sent, including
+// retries
+type RequestHook interface {
+	OnRequest(ctx context.Context, event RequestEvent)
+}
+
+// ResponseHook is called after every REST request completes or fails,
+// including retries
+type ResponseHook interface {
+	OnResponse(ctx context.Context, event ResponseEvent)
+}
+
+// RequestHookFunc adapts a function to RequestHook
+type RequestHookFunc func(ctx context.Context, event RequestEvent)
+
+// OnRequest implements RequestHook
+func (f RequestHookFunc) OnRequest(ctx context.Context, event RequestEvent) {
+	f(ctx, event)
+}
+
+// ResponseHookFunc adapts a function to ResponseHook
+type ResponseHookFunc func(ctx context.Context, event ResponseEvent)
+
+// OnResponse implements ResponseHook
+func (f ResponseHookFunc) OnResponse(ctx context.Context, event ResponseEvent) {
+	f(ctx, event)
+}
+
+// hasHooks reports whether any request or response hook is registered
+func (c *Client) hasHooks() bool {
+	return len(c.requestHooks) > 0 || len(c.responseHooks) > 0
+}
+
+// sanitizePayload decodes a request body into a fresh map with every API
+// key redacted
+func sanitizePayload(jsonData []byte) map[string]interface{} {
+	if len(jsonData) == 0 {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(jsonData))
+	dec.UseNumber()
+	var payload map[string]interface{}
+	if err := dec.Decode(&payload); err != nil {
+		return nil
+	}
+	redactAPIKey(payload)
+	return payload
+}
+
+// redactAPIKey replaces the API key fields of v and of the objects nested in
+// it
+func redactAPIKey(v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			switch strings.ToLower(key) {
+			case "apikey", "api_key":
+				v[key] = redacted
+			default:
+				redactAPIKey(value)
+			}
+		}
+	case []interface{}:
+		for _, value := range v {
+			redactAPIKey(value)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package openalgo
+
+// Logger receives the log records of the client as a message followed by
+// alternating keys and values. *slog.Logger satisfies it. Records never
+// contain the API key or request payloads.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// nopLogger discards every record. It is the default Logger.
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...any) {}
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Warn(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
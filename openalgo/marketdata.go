@@ -1,6 +1,9 @@
 package openalgo
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -12,6 +15,9 @@ type QuotesRequest struct {
 type DepthRequest struct {
 	Symbol   string `json:"symbol"`
 	Exchange string `json:"exchange"`
+	// Levels is the number of bid and ask levels requested, 5 when unset.
+	// Brokers offering deeper books accept 20 or 50.
+	Levels int `json:"depth,omitempty"`
 }
 
 type HistoryRequest struct {
@@ -41,39 +47,49 @@ type ExpiryRequest struct {
 type QuotesResponse struct {
 	Status string `json:"status"`
 	Data   struct {
-		Open      float64 `json:"open"`
-		High      float64 `json:"high"`
-		Low       float64 `json:"low"`
-		LTP       float64 `json:"ltp"`
-		Ask       float64 `json:"ask"`
-		Bid       float64 `json:"bid"`
-		PrevClose float64 `json:"prev_close"`
-		Volume    int64   `json:"volume"`
+		Open      FlexFloat `json:"open"`
+		High      FlexFloat `json:"high"`
+		Low       FlexFloat `json:"low"`
+		LTP       FlexFloat `json:"ltp"`
+		Ask       FlexFloat `json:"ask"`
+		Bid       FlexFloat `json:"bid"`
+		PrevClose FlexFloat `json:"prev_close"`
+		Volume    FlexInt   `json:"volume"`
 	} `json:"data"`
 }
 
 type DepthResponse struct {
-	Status string `json:"status"`
-	Data   struct {
-		Open         float64 `json:"open"`
-		High         float64 `json:"high"`
-		Low          float64 `json:"low"`
-		LTP          float64 `json:"ltp"`
-		LTQ          int     `json:"ltq"`
-		PrevClose    float64 `json:"prev_close"`
-		Volume       int64   `json:"volume"`
-		OI           int64   `json:"oi"`
-		TotalBuyQty  int64   `json:"totalbuyqty"`
-		TotalSellQty int64   `json:"totalsellqty"`
-		Asks         []struct {
-			Price    float64 `json:"price"`
-			Quantity int     `json:"quantity"`
-		} `json:"asks"`
-		Bids []struct {
-			Price    float64 `json:"price"`
-			Quantity int     `json:"quantity"`
-		} `json:"bids"`
-	} `json:"data"`
+	Status string    `json:"status"`
+	Data   DepthData `json:"data"`
+}
+
+// DepthData holds the market depth of an instrument
+type DepthData struct {
+	Open         FlexFloat    `json:"open"`
+	High         FlexFloat    `json:"high"`
+	Low          FlexFloat    `json:"low"`
+	LTP          FlexFloat    `json:"ltp"`
+	LTQ          FlexInt      `json:"ltq"`
+	PrevClose    FlexFloat    `json:"prev_close"`
+	Volume       FlexInt      `json:"volume"`
+	OI           FlexInt      `json:"oi"`
+	TotalBuyQty  FlexInt      `json:"totalbuyqty"`
+	TotalSellQty FlexInt      `json:"totalsellqty"`
+	Asks         []DepthLevel `json:"asks"`
+	Bids         []DepthLevel `json:"bids"`
+}
+
+// DepthLevel is a single price level of the bid or ask ladder
+type DepthLevel struct {
+	Price    FlexFloat `json:"price"`
+	Quantity FlexInt   `json:"quantity"`
+	Orders   FlexInt   `json:"orders,omitempty"`
+}
+
+// HistoryResponse is the typed response of the history endpoint
+type HistoryResponse struct {
+	Status string       `json:"status"`
+	Data   []HistoryBar `json:"data"`
 }
 
 type HistoryBar struct {
@@ -83,27 +99,101 @@ type HistoryBar struct {
 	Low       float64   `json:"low"`
 	Close     float64   `json:"close"`
 	Volume    int64     `json:"volume"`
+	OI        int64     `json:"oi,omitempty"`
+}
+
+// UnmarshalJSON decodes a bar whose timestamp may be sent as an epoch in
+// seconds down to nanoseconds or as a date-time string, and whose numbers
+// may be quoted
+func (b *HistoryBar) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Timestamp json.RawMessage `json:"timestamp"`
+		Open      FlexFloat       `json:"open"`
+		High      FlexFloat       `json:"high"`
+		Low       FlexFloat       `json:"low"`
+		Close     FlexFloat       `json:"close"`
+		Volume    FlexFloat       `json:"volume"`
+		OI        FlexFloat       `json:"oi"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	ts, err := parseTimestamp(raw.Timestamp)
+	if err != nil {
+		return err
+	}
+
+	*b = HistoryBar{
+		Timestamp: ts,
+		Open:      float64(raw.Open),
+		High:      float64(raw.High),
+		Low:       float64(raw.Low),
+		Close:     float64(raw.Close),
+		Volume:    int64(raw.Volume),
+		OI:        int64(raw.OI),
+	}
+	return nil
 }
 
 func (c *Client) Quotes(symbol, exchange string) (map[string]interface{}, error) {
+	return c.QuotesCtx(context.Background(), symbol, exchange)
+}
+
+// QuotesCtx is like Quotes but honors ctx for cancellation and deadlines
+func (c *Client) QuotesCtx(ctx context.Context, symbol, exchange string) (map[string]interface{}, error) {
 	payload := map[string]interface{}{
 		"apikey":   c.apiKey,
 		"symbol":   symbol,
 		"exchange": exchange,
 	}
-	return c.makeRequest("POST", "quotes", payload)
+	return c.makeRequest(ctx, "POST", "quotes", payload)
+}
+
+// Depth returns the market depth of an instrument. An optional levels
+// argument requests a deeper book, such as 20 or 50 levels; the server's
+// default of 5 levels is used otherwise.
+func (c *Client) Depth(symbol, exchange string, levels ...int) (map[string]interface{}, error) {
+	return c.DepthCtx(context.Background(), symbol, exchange, levels...)
 }
 
-func (c *Client) Depth(symbol, exchange string) (map[string]interface{}, error) {
+// DepthCtx is like Depth but honors ctx for cancellation and deadlines
+func (c *Client) DepthCtx(ctx context.Context, symbol, exchange string, levels ...int) (map[string]interface{}, error) {
+	n, err := optionalDepthLevels(levels)
+	if err != nil {
+		return nil, err
+	}
 	payload := map[string]interface{}{
 		"apikey":   c.apiKey,
 		"symbol":   symbol,
 		"exchange": exchange,
 	}
-	return c.makeRequest("POST", "depth", payload)
+	if n > 0 {
+		payload["depth"] = n
+	}
+	return c.makeRequest(ctx, "POST", "depth", payload)
+}
+
+// optionalDepthLevels returns the depth levels passed as an optional
+// argument, 0 when none is given
+func optionalDepthLevels(levels []int) (int, error) {
+	switch {
+	case len(levels) == 0:
+		return 0, nil
+	case len(levels) > 1:
+		return 0, fmt.Errorf("%w: expected at most one depth levels argument, got %d", ErrInvalidParameter, len(levels))
+	case levels[0] < 0:
+		return 0, fmt.Errorf("%w: depth levels must not be negative, got %d", ErrInvalidParameter, levels[0])
+	}
+	return levels[0], nil
 }
 
 func (c *Client) History(symbol, exchange, interval, startDate, endDate string) (map[string]interface{}, error) {
+	return c.HistoryCtx(context.Background(), symbol, exchange, interval, startDate, endDate)
+}
+
+// HistoryCtx is like History but honors ctx for cancellation and deadlines
+func (c *Client) HistoryCtx(ctx context.Context, symbol, exchange, interval, startDate, endDate string) (map[string]interface{}, error) {
 	payload := map[string]interface{}{
 		"apikey":     c.apiKey,
 		"symbol":     symbol,
@@ -112,26 +202,41 @@ func (c *Client) History(symbol, exchange, interval, startDate, endDate string)
 		"start_date": startDate,
 		"end_date":   endDate,
 	}
-	return c.makeRequest("POST", "history", payload)
+	return c.makeRequest(ctx, "POST", "history", payload)
 }
 
 func (c *Client) Intervals() (map[string]interface{}, error) {
+	return c.IntervalsCtx(context.Background())
+}
+
+// IntervalsCtx is like Intervals but honors ctx for cancellation and deadlines
+func (c *Client) IntervalsCtx(ctx context.Context) (map[string]interface{}, error) {
 	payload := map[string]interface{}{
 		"apikey": c.apiKey,
 	}
-	return c.makeRequest("POST", "intervals", payload)
+	return c.makeRequest(ctx, "POST", "intervals", payload)
 }
 
 func (c *Client) Symbol(symbol, exchange string) (map[string]interface{}, error) {
+	return c.SymbolCtx(context.Background(), symbol, exchange)
+}
+
+// SymbolCtx is like Symbol but honors ctx for cancellation and deadlines
+func (c *Client) SymbolCtx(ctx context.Context, symbol, exchange string) (map[string]interface{}, error) {
 	payload := map[string]interface{}{
 		"apikey":   c.apiKey,
 		"symbol":   symbol,
 		"exchange": exchange,
 	}
-	return c.makeRequest("POST", "symbol", payload)
+	return c.makeRequest(ctx, "POST", "symbol", payload)
 }
 
 func (c *Client) Search(query, exchange string) (map[string]interface{}, error) {
+	return c.SearchCtx(context.Background(), query, exchange)
+}
+
+// SearchCtx is like Search but honors ctx for cancellation and deadlines
+func (c *Client) SearchCtx(ctx context.Context, query, exchange string) (map[string]interface{}, error) {
 	payload := map[string]interface{}{
 		"apikey": c.apiKey,
 		"query":  query,
@@ -139,15 +244,92 @@ func (c *Client) Search(query, exchange string) (map[string]interface{}, error)
 	if exchange != "" {
 		payload["exchange"] = exchange
 	}
-	return c.makeRequest("POST", "search", payload)
+	return c.makeRequest(ctx, "POST", "search", payload)
 }
 
 func (c *Client) Expiry(symbol, exchange, instrumentType string) (map[string]interface{}, error) {
+	return c.ExpiryCtx(context.Background(), symbol, exchange, instrumentType)
+}
+
+// ExpiryCtx is like Expiry but honors ctx for cancellation and deadlines
+func (c *Client) ExpiryCtx(ctx context.Context, symbol, exchange, instrumentType string) (map[string]interface{}, error) {
 	payload := map[string]interface{}{
 		"apikey":         c.apiKey,
 		"symbol":         symbol,
 		"exchange":       exchange,
 		"instrumenttype": instrumentType,
 	}
-	return c.makeRequest("POST", "expiry", payload)
-}
\ No newline at end of file
+	return c.makeRequest(ctx, "POST", "expiry", payload)
+}
+
+// QuotesTyped is like QuotesCtx but decodes the response into a QuotesResponse
+func (c *Client) QuotesTyped(ctx context.Context, req QuotesRequest) (*QuotesResponse, error) {
+	payload := map[string]interface{}{
+		"apikey":   c.apiKey,
+		"symbol":   req.Symbol,
+		"exchange": req.Exchange,
+	}
+	var resp QuotesResponse
+	if err := c.decodeRequest(ctx, "POST", "quotes", payload, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DepthTyped is like DepthCtx but decodes the response into a DepthResponse
+// with typed bid and ask ladders of at most req.Levels entries. When Levels
+// is set and the server returns shorter ladders, the response is returned
+// as received together with an error wrapping ErrDepthUnavailable.
+func (c *Client) DepthTyped(ctx context.Context, req DepthRequest) (*DepthResponse, error) {
+	if req.Levels < 0 {
+		return nil, fmt.Errorf("%w: depth levels must not be negative, got %d", ErrInvalidParameter, req.Levels)
+	}
+	payload := map[string]interface{}{
+		"apikey":   c.apiKey,
+		"symbol":   req.Symbol,
+		"exchange": req.Exchange,
+	}
+	if req.Levels > 0 {
+		payload["depth"] = req.Levels
+	}
+	var resp DepthResponse
+	if err := c.decodeRequest(ctx, "POST", "depth", payload, &resp); err != nil {
+		return nil, err
+	}
+	if req.Levels == 0 {
+		return &resp, nil
+	}
+
+	got := len(resp.Data.Bids)
+	if len(resp.Data.Asks) > got {
+		got = len(resp.Data.Asks)
+	}
+	if got < req.Levels {
+		return &resp, fmt.Errorf("%w: requested %d levels for %s:%s, server provides %d", ErrDepthUnavailable, req.Levels, req.Exchange, req.Symbol, got)
+	}
+	if len(resp.Data.Bids) > req.Levels {
+		resp.Data.Bids = resp.Data.Bids[:req.Levels]
+	}
+	if len(resp.Data.Asks) > req.Levels {
+		resp.Data.Asks = resp.Data.Asks[:req.Levels]
+	}
+	return &resp, nil
+}
+
+// HistoryTyped is like HistoryCtx but returns the candles as HistoryBar
+// values with timestamps in IST
+func (c *Client) HistoryTyped(ctx context.Context, req HistoryRequest) ([]HistoryBar, error) {
+	payload := map[string]interface{}{
+		"apikey":     c.apiKey,
+		"symbol":     req.Symbol,
+		"exchange":   req.Exchange,
+		"interval":   req.Interval,
+		"start_date": req.StartDate,
+		"end_date":   req.EndDate,
+	}
+	var resp HistoryResponse
+	if err := c.decodeRequest(ctx, "POST", "history", payload, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
@@ -0,0 +1,144 @@
+package openalgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newDepthServer returns a REST server answering depth requests with a
+// book of levels levels, recording the requested depth in requested
+func newDepthServer(t *testing.T, levels int, requested *interface{}) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		*requested = payload["depth"]
+
+		book := make([]map[string]interface{}, levels)
+		for i := range book {
+			book[i] = map[string]interface{}{"price": fmt.Sprint(100 + i), "quantity": 10}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"data":   map[string]interface{}{"ltp": "100.5", "bids": book, "asks": book},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDepthLevels(t *testing.T) {
+	var requested interface{}
+	srv := newDepthServer(t, 20, &requested)
+	c := NewClient("test-key", srv.URL)
+
+	if _, err := c.Depth("SBIN", "NSE"); err != nil {
+		t.Fatalf("Depth: %v", err)
+	}
+	if requested != nil {
+		t.Errorf("Depth sent depth %v, want none", requested)
+	}
+	if _, err := c.DepthCtx(context.Background(), "SBIN", "NSE", 20); err != nil {
+		t.Fatalf("DepthCtx: %v", err)
+	}
+	if requested != float64(20) {
+		t.Errorf("DepthCtx sent depth %v, want 20", requested)
+	}
+	if _, err := c.Depth("SBIN", "NSE", -1); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("Depth with negative levels = %v, want ErrInvalidParameter", err)
+	}
+}
+
+func TestDepthTypedUnavailableKeepsResponse(t *testing.T) {
+	var requested interface{}
+	srv := newDepthServer(t, 5, &requested)
+	c := NewClient("test-key", srv.URL)
+
+	resp, err := c.DepthTyped(context.Background(), DepthRequest{Symbol: "SBIN", Exchange: "NSE", Levels: 20})
+	if !errors.Is(err, ErrDepthUnavailable) {
+		t.Fatalf("DepthTyped error = %v, want ErrDepthUnavailable", err)
+	}
+	if resp == nil || len(resp.Data.Bids) != 5 || resp.Data.LTP != 100.5 {
+		t.Errorf("DepthTyped response = %+v, want the 5 levels received", resp)
+	}
+
+	resp, err = c.DepthTyped(context.Background(), DepthRequest{Symbol: "SBIN", Exchange: "NSE", Levels: 3})
+	if err != nil {
+		t.Fatalf("DepthTyped: %v", err)
+	}
+	if len(resp.Data.Bids) != 3 || len(resp.Data.Asks) != 3 || resp.Data.Bids[2].Price != 102 {
+		t.Errorf("DepthTyped ladders = %+v, want 3 levels", resp.Data)
+	}
+}
+
+func TestSubscribeDepthLevels(t *testing.T) {
+	server := newFakeWSServer(t)
+	c := newTestWSClient(t, server)
+
+	if err := c.SubscribeDepth([]Instrument{{Exchange: "NSE", Symbol: "SBIN"}}, func(interface{}) {}, 20); err != nil {
+		t.Fatalf("SubscribeDepth: %v", err)
+	}
+	subs := c.activeSubscriptions()
+	if len(subs) != 1 || subs[0].Depth != 20 {
+		t.Errorf("active subscriptions = %+v, want SBIN with 20 levels", subs)
+	}
+	if err := c.SubscribeDepth([]Instrument{{Exchange: "NSE", Symbol: "SBIN"}}, nil, 20, 50); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("SubscribeDepth with two levels = %v, want ErrInvalidParameter", err)
+	}
+}
+
+func TestSubscribeDeeperDepth(t *testing.T) {
+	server := newFakeWSServer(t)
+	server.maxDepth = 20
+	c := newTestWSClient(t, server)
+	sbin := []Instrument{{Exchange: "NSE", Symbol: "SBIN"}}
+	depth := func() int {
+		subs := c.activeSubscriptions()
+		if len(subs) != 1 {
+			t.Fatalf("active subscriptions = %+v, want SBIN only", subs)
+		}
+		return subs[0].Depth
+	}
+	subscribe := func(levels int) (*Subscription, error) {
+		t.Helper()
+		sub, err := c.SubscribeDepthLevels(sbin, levels, func(DepthTick) {})
+		if err != nil {
+			t.Fatalf("SubscribeDepthLevels(%d): %v", levels, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return sub, sub.Ack().Wait(ctx)
+	}
+
+	if _, err := subscribe(5); err != nil {
+		t.Fatalf("Ack of 5 levels: %v", err)
+	}
+	deepest, err := subscribe(50)
+	if err == nil {
+		t.Fatal("Ack of 50 levels succeeded, want a rejection")
+	}
+	if got := depth(); got != 5 {
+		t.Errorf("depth after rejected 50 levels = %d, want 5", got)
+	}
+
+	deeper, err := subscribe(20)
+	if err != nil {
+		t.Fatalf("Ack of 20 levels: %v", err)
+	}
+	waitFor(t, 5*time.Second, "depth 20 to be recorded", func() bool { return depth() == 20 })
+
+	deepest.Unsubscribe()
+	if got := depth(); got != 20 {
+		t.Errorf("depth after removing the 50 levels handler = %d, want 20", got)
+	}
+	deeper.Unsubscribe()
+	if got := depth(); got != 5 {
+		t.Errorf("depth after removing the 20 levels handler = %d, want 5", got)
+	}
+}
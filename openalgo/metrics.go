@@ -0,0 +1,62 @@
+package openalgo
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// MetricsCollector receives measurements of the client's REST and WebSocket
+// activity. Implementations must be safe for concurrent use.
+// PrometheusMetrics is a ready-made implementation.
+type MetricsCollector interface {
+	// ObserveRequest records a REST request attempt to endpoint. err is nil
+	// on success.
+	ObserveRequest(endpoint string, latency time.Duration, err error)
+	// ObserveReconnect records a WebSocket reconnection attempt. err is nil
+	// when the attempt succeeded.
+	ObserveReconnect(attempt int, err error)
+	// ObserveTick records a market data message of instrument and the time
+	// its handlers took to process it
+	ObserveTick(instrument Instrument, mode Mode, handling time.Duration)
+	// ObserveSubscription records the outcome of a subscribe or unsubscribe
+	// message
+	ObserveSubscription(action string, mode Mode, status AckStatus)
+}
+
+// nopMetrics discards every measurement. It is the default
+// MetricsCollector.
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveRequest(string, time.Duration, error) {}
+func (nopMetrics) ObserveReconnect(int, error)                 {}
+func (nopMetrics) ObserveTick(Instrument, Mode, time.Duration) {}
+func (nopMetrics) ObserveSubscription(string, Mode, AckStatus) {}
+
+// errorType returns a short label classifying err for metrics, "success"
+// when err is nil
+func errorType(err error) string {
+	var transport *transportError
+	var apiErr *APIError
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, ErrInvalidAPIKey):
+		return "invalid_api_key"
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, ErrOrderRejected):
+		return "order_rejected"
+	case errors.Is(err, ErrBrokerUnavailable):
+		return "broker_unavailable"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.As(err, &transport):
+		return "transport"
+	case errors.As(err, &apiErr):
+		return "api_error"
+	}
+	return "other"
+}
@@ -0,0 +1,64 @@
+package openalgo
+
+import (
+	"net/http"
+	"time"
+)
+
+// Middleware wraps the transport used for REST calls. Middlewares passed to
+// WithMiddleware are applied in order, the first one seeing each request
+// first.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to http.RoundTripper
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// chainTransport wraps base with middlewares, the first middleware being the
+// outermost
+func chainTransport(base http.RoundTripper, middlewares []Middleware) http.RoundTripper {
+	rt := base
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		rt = middlewares[i](rt)
+	}
+	return rt
+}
+
+// LoggingMiddleware logs every HTTP round trip to logger with its method,
+// path, status and latency. Request and response bodies, which carry the API
+// key, are never logged.
+func LoggingMiddleware(logger Logger) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			latency := time.Since(start)
+			if err != nil {
+				logger.Warn("http request failed", "method", req.Method, "path", req.URL.Path, "latency", latency, "error", err)
+				return nil, err
+			}
+			logger.Debug("http request", "method", req.Method, "path", req.URL.Path, "status", resp.StatusCode, "latency", latency)
+			return resp, nil
+		})
+	}
+}
+
+// HeaderMiddleware sets header on every request, replacing values of the
+// same name set by the client
+func HeaderMiddleware(header http.Header) Middleware {
+	header = header.Clone()
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			// A RoundTripper must not modify the caller's request
+			req = req.Clone(req.Context())
+			for name, values := range header {
+				req.Header[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
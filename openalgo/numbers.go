@@ -0,0 +1,85 @@
+package openalgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FlexFloat is a float64 that decodes from either a JSON number or a numeric
+// string, as the OpenAlgo server sends some amounts as "1234.50". Empty
+// strings and null decode to zero.
+type FlexFloat float64
+
+// UnmarshalJSON implements json.Unmarshaler
+func (f *FlexFloat) UnmarshalJSON(data []byte) error {
+	s, ok, err := flexNumberText(data)
+	if err != nil || !ok {
+		*f = 0
+		return err
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %s: %w", data, err)
+	}
+	*f = FlexFloat(v)
+	return nil
+}
+
+// Float64 returns f as a float64
+func (f FlexFloat) Float64() float64 {
+	return float64(f)
+}
+
+// FlexInt is an int64 that decodes from either a JSON number or a numeric
+// string, as the OpenAlgo server sends some quantities as "10". Whole
+// floating point values such as 10.0 are accepted; empty strings and null
+// decode to zero.
+type FlexInt int64
+
+// UnmarshalJSON implements json.Unmarshaler
+func (i *FlexInt) UnmarshalJSON(data []byte) error {
+	s, ok, err := flexNumberText(data)
+	if err != nil || !ok {
+		*i = 0
+		return err
+	}
+	if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+		*i = FlexInt(v)
+		return nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v != float64(int64(v)) {
+		return fmt.Errorf("invalid integer %s", data)
+	}
+	*i = FlexInt(v)
+	return nil
+}
+
+// Int returns i as an int
+func (i FlexInt) Int() int {
+	return int(i)
+}
+
+// flexNumberText extracts the textual number from a JSON number or string.
+// ok is false for null and empty strings.
+func flexNumberText(data []byte) (string, bool, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || string(data) == "null" {
+		return "", false, nil
+	}
+	if data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return "", false, err
+		}
+		s = strings.TrimSpace(strings.ReplaceAll(s, ",", ""))
+		if s == "" {
+			return "", false, nil
+		}
+		return s, true, nil
+	}
+	return string(data), true, nil
+}
@@ -0,0 +1,341 @@
+package openalgo
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Option configures a Client created by NewClientWithOptions
+type Option func(*clientConfig) error
+
+// clientConfig holds the settings collected from options before the Client
+// is built
+type clientConfig struct {
+	version       string
+	wsURL         string
+	wsPort        int
+	httpClient    *http.Client
+	timeout       time.Duration
+	userAgent     string
+	retry         RetryPolicy
+	rateLimits    RateLimits
+	reconnect     ReconnectPolicy
+	heartbeat     HeartbeatConfig
+	authTimeout   time.Duration
+	ackTimeout    time.Duration
+	logger        Logger
+	wsDialer      *websocket.Dialer
+	wsHeader      http.Header
+	transport     http.RoundTripper
+	middlewares   []Middleware
+	requestHooks  []RequestHook
+	responseHooks []ResponseHook
+	metrics       MetricsCollector
+	tracer        Tracer
+}
+
+func defaultClientConfig() *clientConfig {
+	return &clientConfig{
+		version:     "v1",
+		wsPort:      8765,
+		userAgent:   "openalgo-go/" + Version,
+		retry:       DefaultRetryPolicy(),
+		rateLimits:  DefaultRateLimits(),
+		reconnect:   DefaultReconnectPolicy(),
+		heartbeat:   DefaultHeartbeatConfig(),
+		authTimeout: 10 * time.Second,
+		ackTimeout:  10 * time.Second,
+		logger:      nopLogger{},
+		metrics:     nopMetrics{},
+		wsDialer:    websocket.DefaultDialer,
+	}
+}
+
+// WithAPIVersion sets the REST API version, "v1" by default
+func WithAPIVersion(version string) Option {
+	return func(cfg *clientConfig) error {
+		if version == "" {
+			return fmt.Errorf("API version must not be empty")
+		}
+		cfg.version = version
+		return nil
+	}
+}
+
+// WithWebSocketURL sets the WebSocket URL explicitly instead of deriving it
+// from the host and WebSocket port
+func WithWebSocketURL(wsURL string) Option {
+	return func(cfg *clientConfig) error {
+		u, err := url.Parse(wsURL)
+		if err != nil {
+			return fmt.Errorf("invalid WebSocket URL %q: %w", wsURL, err)
+		}
+		if u.Scheme != "ws" && u.Scheme != "wss" {
+			return fmt.Errorf("WebSocket URL %q must use ws:// or wss://", wsURL)
+		}
+		if u.Host == "" {
+			return fmt.Errorf("WebSocket URL %q has no host", wsURL)
+		}
+		cfg.wsURL = wsURL
+		return nil
+	}
+}
+
+// WithWebSocketPort sets the port used when deriving the WebSocket URL from
+// the host, 8765 by default
+func WithWebSocketPort(port int) Option {
+	return func(cfg *clientConfig) error {
+		if port <= 0 || port > 65535 {
+			return fmt.Errorf("invalid WebSocket port %d", port)
+		}
+		cfg.wsPort = port
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for REST calls
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(cfg *clientConfig) error {
+		if httpClient == nil {
+			return fmt.Errorf("HTTP client must not be nil")
+		}
+		cfg.httpClient = httpClient
+		return nil
+	}
+}
+
+// WithTransport sets the transport used for REST calls, e.g. to configure a
+// proxy, mTLS or connection pooling, without replacing the HTTP client. It
+// takes precedence over the transport of a client passed to WithHTTPClient.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(cfg *clientConfig) error {
+		if transport == nil {
+			return fmt.Errorf("transport must not be nil")
+		}
+		cfg.transport = transport
+		return nil
+	}
+}
+
+// WithMiddleware wraps the REST transport with middlewares. It may be given
+// more than once; middlewares apply in the order they are added, the first
+// one seeing each request first.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(cfg *clientConfig) error {
+		for _, mw := range middlewares {
+			if mw == nil {
+				return fmt.Errorf("middleware must not be nil")
+			}
+		}
+		cfg.middlewares = append(cfg.middlewares, middlewares...)
+		return nil
+	}
+}
+
+// WithRequestHook registers hook to be called before every REST request is
+// sent. It may be given more than once; hooks run in the order added.
+func WithRequestHook(hook RequestHook) Option {
+	return func(cfg *clientConfig) error {
+		if hook == nil {
+			return fmt.Errorf("request hook must not be nil")
+		}
+		cfg.requestHooks = append(cfg.requestHooks, hook)
+		return nil
+	}
+}
+
+// WithResponseHook registers hook to be called after every REST request
+// completes or fails. It may be given more than once; hooks run in the order
+// added.
+func WithResponseHook(hook ResponseHook) Option {
+	return func(cfg *clientConfig) error {
+		if hook == nil {
+			return fmt.Errorf("response hook must not be nil")
+		}
+		cfg.responseHooks = append(cfg.responseHooks, hook)
+		return nil
+	}
+}
+
+// WithMetrics reports the client's REST and WebSocket activity to
+// collector, such as a *PrometheusMetrics. Nothing is collected by default.
+func WithMetrics(collector MetricsCollector) Option {
+	return func(cfg *clientConfig) error {
+		if collector == nil {
+			return fmt.Errorf("metrics collector must not be nil")
+		}
+		cfg.metrics = collector
+		return nil
+	}
+}
+
+// WithTracer starts a span with tracer around every REST call and every
+// tick handed to a subscription handler, and propagates the trace context
+// in the headers of REST requests. Tracing is disabled by default.
+func WithTracer(tracer Tracer) Option {
+	return func(cfg *clientConfig) error {
+		if tracer == nil {
+			return fmt.Errorf("tracer must not be nil")
+		}
+		cfg.tracer = tracer
+		return nil
+	}
+}
+
+// WithTimeout sets the overall timeout of each REST call, 30 seconds by
+// default. When combined with WithHTTPClient the supplied client is copied
+// rather than modified.
+func WithTimeout(timeout time.Duration) Option {
+	return func(cfg *clientConfig) error {
+		if timeout <= 0 {
+			return fmt.Errorf("timeout must be positive, got %s", timeout)
+		}
+		cfg.timeout = timeout
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with REST calls
+func WithUserAgent(userAgent string) Option {
+	return func(cfg *clientConfig) error {
+		if userAgent == "" {
+			return fmt.Errorf("user agent must not be empty")
+		}
+		cfg.userAgent = userAgent
+		return nil
+	}
+}
+
+// WithRetryPolicy sets the retry policy for REST calls. Use
+// WithRetryPolicy(RetryPolicy{}) to disable retries altogether.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(cfg *clientConfig) error {
+		if err := policy.validate(); err != nil {
+			return err
+		}
+		cfg.retry = policy
+		return nil
+	}
+}
+
+// WithRateLimits sets the client-side request budgets. Calls exceeding a
+// budget block until a token is available or their context is done. Use
+// WithRateLimits(RateLimits{}) to disable client-side limiting.
+func WithRateLimits(limits RateLimits) Option {
+	return func(cfg *clientConfig) error {
+		if err := limits.Orders.validate("order"); err != nil {
+			return err
+		}
+		if err := limits.Data.validate("data"); err != nil {
+			return err
+		}
+		if err := limits.Subscriptions.validate("subscription"); err != nil {
+			return err
+		}
+		cfg.rateLimits = limits
+		return nil
+	}
+}
+
+// WithReconnectPolicy sets how a dropped WebSocket connection is
+// re-established. Set Disabled to turn automatic reconnection off.
+func WithReconnectPolicy(policy ReconnectPolicy) Option {
+	return func(cfg *clientConfig) error {
+		if err := policy.validate(); err != nil {
+			return err
+		}
+		cfg.reconnect = policy
+		return nil
+	}
+}
+
+// WithHeartbeat sets the WebSocket ping interval, read deadline and stale
+// instrument detection, DefaultHeartbeatConfig by default
+func WithHeartbeat(config HeartbeatConfig) Option {
+	return func(cfg *clientConfig) error {
+		if err := config.validate(); err != nil {
+			return err
+		}
+		cfg.heartbeat = config
+		return nil
+	}
+}
+
+// WithAuthTimeout sets how long Connect waits for the server to answer the
+// authentication message, 10s by default. 0 returns without waiting.
+func WithAuthTimeout(timeout time.Duration) Option {
+	return func(cfg *clientConfig) error {
+		if timeout < 0 {
+			return fmt.Errorf("auth timeout must not be negative, got %s", timeout)
+		}
+		cfg.authTimeout = timeout
+		return nil
+	}
+}
+
+// WithAckTimeout sets how long a subscription waits for the server's
+// acknowledgement before it is resolved as AckUnknown, 10s by default. 0
+// waits indefinitely.
+func WithAckTimeout(timeout time.Duration) Option {
+	return func(cfg *clientConfig) error {
+		if timeout < 0 {
+			return fmt.Errorf("ack timeout must not be negative, got %s", timeout)
+		}
+		cfg.ackTimeout = timeout
+		return nil
+	}
+}
+
+// WithWebSocketDialer sets the dialer used to open WebSocket connections,
+// e.g. to configure TLS root CAs, client certificates, a proxy or the
+// handshake timeout. websocket.DefaultDialer is used by default.
+func WithWebSocketDialer(dialer *websocket.Dialer) Option {
+	return func(cfg *clientConfig) error {
+		if dialer == nil {
+			return fmt.Errorf("WebSocket dialer must not be nil")
+		}
+		d := *dialer
+		cfg.wsDialer = &d
+		return nil
+	}
+}
+
+// WithWebSocketHeader sets extra HTTP headers sent with the WebSocket
+// handshake, such as authentication for a reverse proxy
+func WithWebSocketHeader(header http.Header) Option {
+	return func(cfg *clientConfig) error {
+		cfg.wsHeader = header.Clone()
+		return nil
+	}
+}
+
+// WithLogger routes the client's log records to logger, such as a
+// *slog.Logger. Nothing is logged by default.
+func WithLogger(logger Logger) Option {
+	return func(cfg *clientConfig) error {
+		if logger == nil {
+			return fmt.Errorf("logger must not be nil")
+		}
+		cfg.logger = logger
+		return nil
+	}
+}
+
+// validateHost checks that host is an absolute http or https URL
+func validateHost(host string) error {
+	u, err := url.Parse(host)
+	if err != nil {
+		return fmt.Errorf("invalid host %q: %w", host, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("host %q must use http:// or https://", host)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("host %q has no hostname", host)
+	}
+	return nil
+}
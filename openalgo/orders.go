@@ -1,11 +1,18 @@
 package openalgo
 
 import (
+	"context"
 	"fmt"
+	"strings"
 )
 
 // PlaceOrder places a new order
 func (c *Client) PlaceOrder(strategy, symbol, action, exchange, priceType, product string, quantity interface{}, optionalParams ...map[string]interface{}) (map[string]interface{}, error) {
+	return c.PlaceOrderCtx(context.Background(), strategy, symbol, action, exchange, priceType, product, quantity, optionalParams...)
+}
+
+// PlaceOrderCtx is like PlaceOrder but honors ctx for cancellation and deadlines
+func (c *Client) PlaceOrderCtx(ctx context.Context, strategy, symbol, action, exchange, priceType, product string, quantity interface{}, optionalParams ...map[string]interface{}) (map[string]interface{}, error) {
 	// Set defaults
 	if strategy == "" {
 		strategy = "GO Strategy"
@@ -28,42 +35,26 @@ func (c *Client) PlaceOrder(strategy, symbol, action, exchange, priceType, produ
 	}
 
 	// Convert quantity to string
-	switch v := quantity.(type) {
-	case string:
-		payload["quantity"] = v
-	case int:
-		payload["quantity"] = fmt.Sprintf("%d", v)
-	case float64:
-		payload["quantity"] = fmt.Sprintf("%.0f", v)
-	default:
-		payload["quantity"] = "1"
-	}
-
-	// Add optional parameters
-	if len(optionalParams) > 0 {
-		params := optionalParams[0]
-		for key, value := range params {
-			if value != nil {
-				// Convert all values to strings
-				switch v := value.(type) {
-				case string:
-					payload[key] = v
-				case int:
-					payload[key] = fmt.Sprintf("%d", v)
-				case float64:
-					payload[key] = fmt.Sprintf("%g", v)
-				default:
-					payload[key] = fmt.Sprintf("%v", v)
-				}
-			}
-		}
+	quantityStr, err := quantityParam("quantity", quantity)
+	if err != nil {
+		return nil, err
 	}
+	payload["quantity"] = quantityStr
 
-	return c.makeRequest("POST", "placeorder", payload)
+	if err := addOptionalParams(payload, optionalParams); err != nil {
+		return nil, err
+	}
+
+	return c.makeRequest(ctx, "POST", "placeorder", payload)
 }
 
 // PlaceSmartOrder places a smart order considering position size
 func (c *Client) PlaceSmartOrder(strategy, symbol, action, exchange, priceType, product string, quantity interface{}, positionSize interface{}, optionalParams ...map[string]interface{}) (map[string]interface{}, error) {
+	return c.PlaceSmartOrderCtx(context.Background(), strategy, symbol, action, exchange, priceType, product, quantity, positionSize, optionalParams...)
+}
+
+// PlaceSmartOrderCtx is like PlaceSmartOrder but honors ctx for cancellation and deadlines
+func (c *Client) PlaceSmartOrderCtx(ctx context.Context, strategy, symbol, action, exchange, priceType, product string, quantity interface{}, positionSize interface{}, optionalParams ...map[string]interface{}) (map[string]interface{}, error) {
 	// Set defaults
 	if strategy == "" {
 		strategy = "GO Strategy"
@@ -86,53 +77,33 @@ func (c *Client) PlaceSmartOrder(strategy, symbol, action, exchange, priceType,
 	}
 
 	// Convert quantity to string
-	switch v := quantity.(type) {
-	case string:
-		payload["quantity"] = v
-	case int:
-		payload["quantity"] = fmt.Sprintf("%d", v)
-	case float64:
-		payload["quantity"] = fmt.Sprintf("%.0f", v)
-	default:
-		payload["quantity"] = "1"
+	quantityStr, err := quantityParam("quantity", quantity)
+	if err != nil {
+		return nil, err
 	}
+	payload["quantity"] = quantityStr
 
 	// Convert position_size to string
-	switch v := positionSize.(type) {
-	case string:
-		payload["position_size"] = v
-	case int:
-		payload["position_size"] = fmt.Sprintf("%d", v)
-	case float64:
-		payload["position_size"] = fmt.Sprintf("%.0f", v)
-	default:
-		return nil, fmt.Errorf("position_size is required")
-	}
-
-	// Add optional parameters
-	if len(optionalParams) > 0 {
-		params := optionalParams[0]
-		for key, value := range params {
-			if value != nil {
-				switch v := value.(type) {
-				case string:
-					payload[key] = v
-				case int:
-					payload[key] = fmt.Sprintf("%d", v)
-				case float64:
-					payload[key] = fmt.Sprintf("%g", v)
-				default:
-					payload[key] = fmt.Sprintf("%v", v)
-				}
-			}
-		}
+	positionSizeStr, err := quantityParam("position_size", positionSize)
+	if err != nil {
+		return nil, err
+	}
+	payload["position_size"] = positionSizeStr
+
+	if err := addOptionalParams(payload, optionalParams); err != nil {
+		return nil, err
 	}
 
-	return c.makeRequest("POST", "placesmartorder", payload)
+	return c.makeRequest(ctx, "POST", "placesmartorder", payload)
 }
 
 // BasketOrder places multiple orders at once
 func (c *Client) BasketOrder(strategy string, orders []map[string]interface{}) (map[string]interface{}, error) {
+	return c.BasketOrderCtx(context.Background(), strategy, orders)
+}
+
+// BasketOrderCtx is like BasketOrder but honors ctx for cancellation and deadlines
+func (c *Client) BasketOrderCtx(ctx context.Context, strategy string, orders []map[string]interface{}) (map[string]interface{}, error) {
 	if strategy == "" {
 		strategy = "GO Strategy"
 	}
@@ -142,11 +113,17 @@ func (c *Client) BasketOrder(strategy string, orders []map[string]interface{}) (
 	for i, order := range orders {
 		processedOrder := make(map[string]interface{})
 		for key, value := range order {
+			if quantityKeys[key] {
+				qty, err := quantityParam(fmt.Sprintf("orders[%d].%s", i, key), value)
+				if err != nil {
+					return nil, err
+				}
+				processedOrder[key] = qty
+				continue
+			}
 			switch v := value.(type) {
-			case int:
-				processedOrder[key] = fmt.Sprintf("%d", v)
-			case float64:
-				processedOrder[key] = fmt.Sprintf("%g", v)
+			case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, Price, Quantity:
+				processedOrder[key] = formatParam(v)
 			default:
 				processedOrder[key] = v
 			}
@@ -160,11 +137,44 @@ func (c *Client) BasketOrder(strategy string, orders []map[string]interface{}) (
 		"orders":   processedOrders,
 	}
 
-	return c.makeRequest("POST", "basketorder", payload)
+	return c.makeRequest(ctx, "POST", "basketorder", payload)
+}
+
+// quantityKeys are the order parameters holding a number of shares, which
+// must be whole
+var quantityKeys = map[string]bool{"quantity": true, "disclosed_quantity": true}
+
+// addOptionalParams adds the first of optionalParams to payload, converting
+// every value to a string: quantities must be whole numbers and prices are
+// sent as exact decimals
+func addOptionalParams(payload map[string]interface{}, optionalParams []map[string]interface{}) error {
+	if len(optionalParams) == 0 {
+		return nil
+	}
+	for key, value := range optionalParams[0] {
+		if value == nil {
+			continue
+		}
+		if quantityKeys[key] {
+			qty, err := quantityParam(key, value)
+			if err != nil {
+				return err
+			}
+			payload[key] = qty
+			continue
+		}
+		payload[key] = formatParam(value)
+	}
+	return nil
 }
 
 // SplitOrder splits a large order into smaller orders
 func (c *Client) SplitOrder(strategy, symbol, exchange, action string, quantity, splitSize interface{}, priceType, product string, optionalParams ...map[string]interface{}) (map[string]interface{}, error) {
+	return c.SplitOrderCtx(context.Background(), strategy, symbol, exchange, action, quantity, splitSize, priceType, product, optionalParams...)
+}
+
+// SplitOrderCtx is like SplitOrder but honors ctx for cancellation and deadlines
+func (c *Client) SplitOrderCtx(ctx context.Context, strategy, symbol, exchange, action string, quantity, splitSize interface{}, priceType, product string, optionalParams ...map[string]interface{}) (map[string]interface{}, error) {
 	// Set defaults
 	if strategy == "" {
 		strategy = "GO Strategy"
@@ -187,53 +197,33 @@ func (c *Client) SplitOrder(strategy, symbol, exchange, action string, quantity,
 	}
 
 	// Convert quantity to string
-	switch v := quantity.(type) {
-	case string:
-		payload["quantity"] = v
-	case int:
-		payload["quantity"] = fmt.Sprintf("%d", v)
-	case float64:
-		payload["quantity"] = fmt.Sprintf("%.0f", v)
-	default:
-		return nil, fmt.Errorf("quantity is required")
+	quantityStr, err := quantityParam("quantity", quantity)
+	if err != nil {
+		return nil, err
 	}
+	payload["quantity"] = quantityStr
 
 	// Convert splitsize to string
-	switch v := splitSize.(type) {
-	case string:
-		payload["splitsize"] = v
-	case int:
-		payload["splitsize"] = fmt.Sprintf("%d", v)
-	case float64:
-		payload["splitsize"] = fmt.Sprintf("%.0f", v)
-	default:
-		return nil, fmt.Errorf("splitsize is required")
-	}
-
-	// Add optional parameters
-	if len(optionalParams) > 0 {
-		params := optionalParams[0]
-		for key, value := range params {
-			if value != nil {
-				switch v := value.(type) {
-				case string:
-					payload[key] = v
-				case int:
-					payload[key] = fmt.Sprintf("%d", v)
-				case float64:
-					payload[key] = fmt.Sprintf("%g", v)
-				default:
-					payload[key] = fmt.Sprintf("%v", v)
-				}
-			}
-		}
+	splitSizeStr, err := quantityParam("splitsize", splitSize)
+	if err != nil {
+		return nil, err
+	}
+	payload["splitsize"] = splitSizeStr
+
+	if err := addOptionalParams(payload, optionalParams); err != nil {
+		return nil, err
 	}
 
-	return c.makeRequest("POST", "splitorder", payload)
+	return c.makeRequest(ctx, "POST", "splitorder", payload)
 }
 
 // ModifyOrder modifies an existing order
 func (c *Client) ModifyOrder(orderID, strategy, symbol, action, exchange, priceType, product string, quantity interface{}, price, disclosedQuantity, triggerPrice string) (map[string]interface{}, error) {
+	return c.ModifyOrderCtx(context.Background(), orderID, strategy, symbol, action, exchange, priceType, product, quantity, price, disclosedQuantity, triggerPrice)
+}
+
+// ModifyOrderCtx is like ModifyOrder but honors ctx for cancellation and deadlines
+func (c *Client) ModifyOrderCtx(ctx context.Context, orderID, strategy, symbol, action, exchange, priceType, product string, quantity interface{}, price, disclosedQuantity, triggerPrice string) (map[string]interface{}, error) {
 	// Set defaults
 	if strategy == "" {
 		strategy = "GO Strategy"
@@ -249,36 +239,36 @@ func (c *Client) ModifyOrder(orderID, strategy, symbol, action, exchange, priceT
 	}
 
 	payload := map[string]interface{}{
-		"apikey":              c.apiKey,
-		"orderid":             orderID,
-		"strategy":            strategy,
-		"symbol":              symbol,
-		"action":              action,
-		"exchange":            exchange,
-		"pricetype":           priceType,
-		"product":             product,
-		"price":               price,
-		"disclosed_quantity":  disclosedQuantity,
-		"trigger_price":       triggerPrice,
+		"apikey":             c.apiKey,
+		"orderid":            orderID,
+		"strategy":           strategy,
+		"symbol":             symbol,
+		"action":             action,
+		"exchange":           exchange,
+		"pricetype":          priceType,
+		"product":            product,
+		"price":              price,
+		"disclosed_quantity": disclosedQuantity,
+		"trigger_price":      triggerPrice,
 	}
 
 	// Convert quantity to string
-	switch v := quantity.(type) {
-	case string:
-		payload["quantity"] = v
-	case int:
-		payload["quantity"] = fmt.Sprintf("%d", v)
-	case float64:
-		payload["quantity"] = fmt.Sprintf("%.0f", v)
-	default:
-		return nil, fmt.Errorf("quantity is required")
+	quantityStr, err := quantityParam("quantity", quantity)
+	if err != nil {
+		return nil, err
 	}
+	payload["quantity"] = quantityStr
 
-	return c.makeRequest("POST", "modifyorder", payload)
+	return c.makeRequest(ctx, "POST", "modifyorder", payload)
 }
 
 // CancelOrder cancels an existing order
 func (c *Client) CancelOrder(orderID, strategy string) (map[string]interface{}, error) {
+	return c.CancelOrderCtx(context.Background(), orderID, strategy)
+}
+
+// CancelOrderCtx is like CancelOrder but honors ctx for cancellation and deadlines
+func (c *Client) CancelOrderCtx(ctx context.Context, orderID, strategy string) (map[string]interface{}, error) {
 	if strategy == "" {
 		strategy = "GO Strategy"
 	}
@@ -289,11 +279,16 @@ func (c *Client) CancelOrder(orderID, strategy string) (map[string]interface{},
 		"strategy": strategy,
 	}
 
-	return c.makeRequest("POST", "cancelorder", payload)
+	return c.makeRequest(ctx, "POST", "cancelorder", payload)
 }
 
 // CancelAllOrder cancels all orders for a strategy
 func (c *Client) CancelAllOrder(strategy string) (map[string]interface{}, error) {
+	return c.CancelAllOrderCtx(context.Background(), strategy)
+}
+
+// CancelAllOrderCtx is like CancelAllOrder but honors ctx for cancellation and deadlines
+func (c *Client) CancelAllOrderCtx(ctx context.Context, strategy string) (map[string]interface{}, error) {
 	if strategy == "" {
 		strategy = "GO Strategy"
 	}
@@ -303,11 +298,16 @@ func (c *Client) CancelAllOrder(strategy string) (map[string]interface{}, error)
 		"strategy": strategy,
 	}
 
-	return c.makeRequest("POST", "cancelallorder", payload)
+	return c.makeRequest(ctx, "POST", "cancelallorder", payload)
 }
 
 // ClosePosition closes all open positions for a strategy
 func (c *Client) ClosePosition(strategy string) (map[string]interface{}, error) {
+	return c.ClosePositionCtx(context.Background(), strategy)
+}
+
+// ClosePositionCtx is like ClosePosition but honors ctx for cancellation and deadlines
+func (c *Client) ClosePositionCtx(ctx context.Context, strategy string) (map[string]interface{}, error) {
 	if strategy == "" {
 		strategy = "GO Strategy"
 	}
@@ -317,11 +317,16 @@ func (c *Client) ClosePosition(strategy string) (map[string]interface{}, error)
 		"strategy": strategy,
 	}
 
-	return c.makeRequest("POST", "closeposition", payload)
+	return c.makeRequest(ctx, "POST", "closeposition", payload)
 }
 
 // OrderStatus gets the status of an order
 func (c *Client) OrderStatus(orderID, strategy string) (map[string]interface{}, error) {
+	return c.OrderStatusCtx(context.Background(), orderID, strategy)
+}
+
+// OrderStatusCtx is like OrderStatus but honors ctx for cancellation and deadlines
+func (c *Client) OrderStatusCtx(ctx context.Context, orderID, strategy string) (map[string]interface{}, error) {
 	if strategy == "" {
 		strategy = "GO Strategy"
 	}
@@ -332,11 +337,16 @@ func (c *Client) OrderStatus(orderID, strategy string) (map[string]interface{},
 		"orderid":  orderID,
 	}
 
-	return c.makeRequest("POST", "orderstatus", payload)
+	return c.makeRequest(ctx, "POST", "orderstatus", payload)
 }
 
 // OpenPosition gets the open position for a symbol
 func (c *Client) OpenPosition(strategy, symbol, exchange, product string) (map[string]interface{}, error) {
+	return c.OpenPositionCtx(context.Background(), strategy, symbol, exchange, product)
+}
+
+// OpenPositionCtx is like OpenPosition but honors ctx for cancellation and deadlines
+func (c *Client) OpenPositionCtx(ctx context.Context, strategy, symbol, exchange, product string) (map[string]interface{}, error) {
 	if strategy == "" {
 		strategy = "GO Strategy"
 	}
@@ -349,5 +359,362 @@ func (c *Client) OpenPosition(strategy, symbol, exchange, product string) (map[s
 		"product":  product,
 	}
 
-	return c.makeRequest("POST", "openposition", payload)
-}
\ No newline at end of file
+	return c.makeRequest(ctx, "POST", "openposition", payload)
+}
+
+// defaultStrategy is the strategy name used when none is given
+const defaultStrategy = "GO Strategy"
+
+// OrderRequest describes an order for PlaceOrderTyped. Strategy, PriceType
+// and Product default to "GO Strategy", MARKET and MIS when left empty.
+type OrderRequest struct {
+	Strategy          string
+	Symbol            string
+	Action            Action
+	Exchange          Exchange
+	PriceType         PriceType
+	Product           Product
+	Quantity          Quantity
+	Price             Price
+	TriggerPrice      Price
+	DisclosedQuantity Quantity
+}
+
+// SmartOrderRequest describes an order for PlaceSmartOrderTyped, which
+// trades only the difference between the current and the target position
+type SmartOrderRequest struct {
+	OrderRequest
+	PositionSize Quantity
+}
+
+// SplitOrderRequest describes an order for SplitOrderTyped, which places
+// Quantity as several orders of at most SplitSize each
+type SplitOrderRequest struct {
+	OrderRequest
+	SplitSize Quantity
+}
+
+// ModifyOrderRequest describes the new parameters of an open order for
+// ModifyOrderTyped. PriceType defaults to LIMIT when left empty.
+type ModifyOrderRequest struct {
+	OrderID string
+	OrderRequest
+}
+
+// OrderResponse is the typed response of the order endpoints
+type OrderResponse struct {
+	Status  string `json:"status"`
+	OrderID string `json:"orderid"`
+}
+
+// SplitOrderResponse is the typed response of the splitorder endpoint
+type SplitOrderResponse struct {
+	Status        string             `json:"status"`
+	SplitSize     FlexInt            `json:"split_size"`
+	TotalQuantity FlexInt            `json:"total_quantity"`
+	Results       []SplitOrderResult `json:"results"`
+}
+
+// SplitOrderResult is the outcome of one of the orders of a split order
+type SplitOrderResult struct {
+	OrderNum int     `json:"order_num"`
+	OrderID  string  `json:"orderid"`
+	Quantity FlexInt `json:"quantity"`
+	Status   string  `json:"status"`
+	Message  string  `json:"message,omitempty"`
+}
+
+// payload builds the request body shared by the order endpoints
+func (r OrderRequest) payload(apiKey string, defaultPriceType PriceType) map[string]interface{} {
+	strategy := r.Strategy
+	if strategy == "" {
+		strategy = defaultStrategy
+	}
+	priceType := r.PriceType
+	if priceType == "" {
+		priceType = defaultPriceType
+	}
+	product := r.Product
+	if product == "" {
+		product = ProductMIS
+	}
+
+	payload := map[string]interface{}{
+		"apikey":    apiKey,
+		"strategy":  strategy,
+		"symbol":    r.Symbol,
+		"action":    string(r.Action),
+		"exchange":  string(r.Exchange),
+		"pricetype": string(priceType),
+		"product":   string(product),
+		"quantity":  r.Quantity.String(),
+	}
+	if !r.Price.IsZero() {
+		payload["price"] = r.Price.String()
+	}
+	if !r.TriggerPrice.IsZero() {
+		payload["trigger_price"] = r.TriggerPrice.String()
+	}
+	if r.DisclosedQuantity != 0 {
+		payload["disclosed_quantity"] = r.DisclosedQuantity.String()
+	}
+	return payload
+}
+
+// PlaceOrderTyped validates and places a new order described by req
+func (c *Client) PlaceOrderTyped(ctx context.Context, req OrderRequest) (*OrderResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var resp OrderResponse
+	payload := req.payload(c.apiKey, PriceTypeMarket)
+	if err := c.decodeRequest(ctx, "POST", "placeorder", payload, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// PlaceSmartOrderTyped validates and places a smart order described by req
+func (c *Client) PlaceSmartOrderTyped(ctx context.Context, req SmartOrderRequest) (*OrderResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var resp OrderResponse
+	payload := req.payload(c.apiKey, PriceTypeMarket)
+	payload["position_size"] = req.PositionSize.String()
+	if err := c.decodeRequest(ctx, "POST", "placesmartorder", payload, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SplitOrderTyped validates the order described by req and splits it into
+// smaller orders
+func (c *Client) SplitOrderTyped(ctx context.Context, req SplitOrderRequest) (*SplitOrderResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var resp SplitOrderResponse
+	payload := req.payload(c.apiKey, PriceTypeMarket)
+	payload["splitsize"] = req.SplitSize.String()
+	if err := c.decodeRequest(ctx, "POST", "splitorder", payload, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ModifyOrderTyped validates req and modifies the open order req.OrderID
+func (c *Client) ModifyOrderTyped(ctx context.Context, req ModifyOrderRequest) (*OrderResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var resp OrderResponse
+	payload := req.payload(c.apiKey, PriceTypeLimit)
+	payload["orderid"] = req.OrderID
+	// modifyorder expects every price field to be present
+	payload["price"] = req.Price.String()
+	payload["trigger_price"] = req.TriggerPrice.String()
+	payload["disclosed_quantity"] = req.DisclosedQuantity.String()
+	if err := c.decodeRequest(ctx, "POST", "modifyorder", payload, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// FieldError describes a single invalid field of an order request
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// Error implements the error interface
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationError lists every problem found while validating an order
+// request. It matches ErrInvalidParameter with errors.Is.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Error()
+	}
+	return fmt.Sprintf("invalid order: %s", strings.Join(msgs, "; "))
+}
+
+// Unwrap returns ErrInvalidParameter
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidParameter
+}
+
+// cashExchanges are the segments where delivery (CNC) products are allowed
+// and carry-forward derivative (NRML) products are not
+var cashExchanges = map[Exchange]bool{
+	ExchangeNSE: true,
+	ExchangeBSE: true,
+}
+
+// tradableExchanges are the segments orders can be placed on
+var tradableExchanges = map[Exchange]bool{
+	ExchangeNSE: true,
+	ExchangeBSE: true,
+	ExchangeNFO: true,
+	ExchangeBFO: true,
+	ExchangeCDS: true,
+	ExchangeBCD: true,
+	ExchangeMCX: true,
+}
+
+// Validate checks r before it is sent to the server and returns a
+// *ValidationError listing every problem found
+func (r OrderRequest) Validate() error {
+	errs := r.validate(PriceTypeMarket)
+	if r.Quantity <= 0 {
+		errs = append(errs, FieldError{"quantity", "must be positive"})
+	}
+	return validationResult(errs)
+}
+
+// Validate checks r before it is sent to the server. Unlike a plain order a
+// zero quantity is allowed, as the server derives it from PositionSize.
+func (r SmartOrderRequest) Validate() error {
+	errs := r.validate(PriceTypeMarket)
+	if r.Quantity < 0 {
+		errs = append(errs, FieldError{"quantity", "must not be negative"})
+	}
+	return validationResult(errs)
+}
+
+// Validate checks r before it is sent to the server
+func (r SplitOrderRequest) Validate() error {
+	errs := r.validate(PriceTypeMarket)
+	if r.Quantity <= 0 {
+		errs = append(errs, FieldError{"quantity", "must be positive"})
+	}
+	if r.SplitSize <= 0 {
+		errs = append(errs, FieldError{"splitsize", "must be positive"})
+	}
+	return validationResult(errs)
+}
+
+// Validate checks r before it is sent to the server
+func (r ModifyOrderRequest) Validate() error {
+	var errs []FieldError
+	if r.OrderID == "" {
+		errs = append(errs, FieldError{"orderid", "is required"})
+	}
+	errs = append(errs, r.validate(PriceTypeLimit)...)
+	if r.Quantity <= 0 {
+		errs = append(errs, FieldError{"quantity", "must be positive"})
+	}
+	return validationResult(errs)
+}
+
+// validate runs the checks shared by every order request. Quantity is
+// checked by the callers as the rules differ per endpoint.
+func (r OrderRequest) validate(defaultPriceType PriceType) []FieldError {
+	var errs []FieldError
+
+	if r.Symbol == "" {
+		errs = append(errs, FieldError{"symbol", "is required"})
+	}
+
+	switch r.Action {
+	case ActionBuy, ActionSell:
+	case "":
+		errs = append(errs, FieldError{"action", "is required"})
+	default:
+		errs = append(errs, FieldError{"action", fmt.Sprintf("unknown action %q", r.Action)})
+	}
+
+	switch {
+	case r.Exchange == "":
+		errs = append(errs, FieldError{"exchange", "is required"})
+	case !tradableExchanges[r.Exchange]:
+		errs = append(errs, FieldError{"exchange", fmt.Sprintf("orders cannot be placed on %q", r.Exchange)})
+	}
+
+	product := r.Product
+	if product == "" {
+		product = ProductMIS
+	}
+	switch product {
+	case ProductMIS:
+	case ProductCNC:
+		if r.Exchange != "" && !cashExchanges[r.Exchange] {
+			errs = append(errs, FieldError{"product", fmt.Sprintf("CNC is not allowed on %s", r.Exchange)})
+		}
+	case ProductNRML:
+		if cashExchanges[r.Exchange] {
+			errs = append(errs, FieldError{"product", fmt.Sprintf("NRML is not allowed on %s", r.Exchange)})
+		}
+	default:
+		errs = append(errs, FieldError{"product", fmt.Sprintf("unknown product %q", r.Product)})
+	}
+
+	if r.Price.Sign() < 0 {
+		errs = append(errs, FieldError{"price", "must not be negative"})
+	}
+	if r.TriggerPrice.Sign() < 0 {
+		errs = append(errs, FieldError{"trigger_price", "must not be negative"})
+	}
+	if r.DisclosedQuantity < 0 {
+		errs = append(errs, FieldError{"disclosed_quantity", "must not be negative"})
+	} else if r.DisclosedQuantity > r.Quantity && r.Quantity > 0 {
+		errs = append(errs, FieldError{"disclosed_quantity", "must not exceed quantity"})
+	}
+
+	priceType := r.PriceType
+	if priceType == "" {
+		priceType = defaultPriceType
+	}
+	switch priceType {
+	case PriceTypeMarket:
+	case PriceTypeLimit:
+		if r.Price.Sign() <= 0 {
+			errs = append(errs, FieldError{"price", "is required for LIMIT orders"})
+		}
+	case PriceTypeSL:
+		if r.Price.Sign() <= 0 {
+			errs = append(errs, FieldError{"price", "is required for SL orders"})
+		}
+		if r.TriggerPrice.Sign() <= 0 {
+			errs = append(errs, FieldError{"trigger_price", "is required for SL orders"})
+		}
+		if r.Price.Sign() > 0 && r.TriggerPrice.Sign() > 0 {
+			// A stop-loss buy triggers on the way up, a sell on the way down
+			if r.Action == ActionBuy && r.TriggerPrice.Cmp(r.Price) > 0 {
+				errs = append(errs, FieldError{"trigger_price", "must not be above price for SL buy orders"})
+			}
+			if r.Action == ActionSell && r.TriggerPrice.Cmp(r.Price) < 0 {
+				errs = append(errs, FieldError{"trigger_price", "must not be below price for SL sell orders"})
+			}
+		}
+	case PriceTypeSLM:
+		if r.TriggerPrice.Sign() <= 0 {
+			errs = append(errs, FieldError{"trigger_price", "is required for SL-M orders"})
+		}
+	default:
+		errs = append(errs, FieldError{"pricetype", fmt.Sprintf("unknown price type %q", r.PriceType)})
+	}
+
+	return errs
+}
+
+// validationResult wraps errs in a *ValidationError, or returns nil when
+// there is nothing to report
+func validationResult(errs []FieldError) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: errs}
+}
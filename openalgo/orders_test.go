@@ -0,0 +1,257 @@
+package openalgo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func mustPrice(t *testing.T, s string) Price {
+	t.Helper()
+	p, err := ParsePrice(s)
+	if err != nil {
+		t.Fatalf("ParsePrice(%q): %v", s, err)
+	}
+	return p
+}
+
+func TestOrderRequestValidate(t *testing.T) {
+	base := OrderRequest{
+		Strategy:  "test",
+		Symbol:    "SBIN",
+		Action:    ActionBuy,
+		Exchange:  ExchangeNSE,
+		PriceType: PriceTypeMarket,
+		Product:   ProductMIS,
+		Quantity:  10,
+	}
+
+	tests := []struct {
+		name   string
+		modify func(r *OrderRequest)
+		fields []string
+	}{
+		{
+			name:   "valid market order",
+			modify: func(r *OrderRequest) {},
+		},
+		{
+			name: "valid limit order",
+			modify: func(r *OrderRequest) {
+				r.PriceType = PriceTypeLimit
+				r.Price = mustPrice(t, "820.50")
+			},
+		},
+		{
+			name:   "limit without price",
+			modify: func(r *OrderRequest) { r.PriceType = PriceTypeLimit },
+			fields: []string{"price"},
+		},
+		{
+			name: "SL without trigger price",
+			modify: func(r *OrderRequest) {
+				r.PriceType = PriceTypeSL
+				r.Price = mustPrice(t, "820")
+			},
+			fields: []string{"trigger_price"},
+		},
+		{
+			name:   "SL-M without trigger price",
+			modify: func(r *OrderRequest) { r.PriceType = PriceTypeSLM },
+			fields: []string{"trigger_price"},
+		},
+		{
+			name: "valid SL buy",
+			modify: func(r *OrderRequest) {
+				r.PriceType = PriceTypeSL
+				r.Price = mustPrice(t, "820")
+				r.TriggerPrice = mustPrice(t, "819.5")
+			},
+		},
+		{
+			name: "SL buy with trigger above price",
+			modify: func(r *OrderRequest) {
+				r.PriceType = PriceTypeSL
+				r.Price = mustPrice(t, "820")
+				r.TriggerPrice = mustPrice(t, "820.05")
+			},
+			fields: []string{"trigger_price"},
+		},
+		{
+			name: "valid SL sell",
+			modify: func(r *OrderRequest) {
+				r.Action = ActionSell
+				r.PriceType = PriceTypeSL
+				r.Price = mustPrice(t, "820")
+				r.TriggerPrice = mustPrice(t, "820.5")
+			},
+		},
+		{
+			name: "SL sell with trigger below price",
+			modify: func(r *OrderRequest) {
+				r.Action = ActionSell
+				r.PriceType = PriceTypeSL
+				r.Price = mustPrice(t, "820")
+				r.TriggerPrice = mustPrice(t, "819.95")
+			},
+			fields: []string{"trigger_price"},
+		},
+		{
+			name: "CNC on NFO",
+			modify: func(r *OrderRequest) {
+				r.Exchange = ExchangeNFO
+				r.Product = ProductCNC
+			},
+			fields: []string{"product"},
+		},
+		{
+			name:   "NRML on NSE",
+			modify: func(r *OrderRequest) { r.Product = ProductNRML },
+			fields: []string{"product"},
+		},
+		{
+			name: "NRML on NFO",
+			modify: func(r *OrderRequest) {
+				r.Exchange = ExchangeNFO
+				r.Product = ProductNRML
+			},
+		},
+		{
+			name:   "zero quantity",
+			modify: func(r *OrderRequest) { r.Quantity = 0 },
+			fields: []string{"quantity"},
+		},
+		{
+			name:   "negative quantity",
+			modify: func(r *OrderRequest) { r.Quantity = -5 },
+			fields: []string{"quantity"},
+		},
+		{
+			name:   "disclosed quantity above quantity",
+			modify: func(r *OrderRequest) { r.DisclosedQuantity = 11 },
+			fields: []string{"disclosed_quantity"},
+		},
+		{
+			name:   "disclosed quantity equal to quantity",
+			modify: func(r *OrderRequest) { r.DisclosedQuantity = 10 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := base
+			tt.modify(&r)
+			err := r.Validate()
+			if len(tt.fields) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate() = %v, want *ValidationError", err)
+			}
+			var fields []string
+			for _, fe := range verr.Errors {
+				fields = append(fields, fe.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("invalid fields = %v, want %v (%v)", fields, tt.fields, err)
+			}
+		})
+	}
+}
+
+func TestOrderRequestValidateListsEveryProblem(t *testing.T) {
+	r := OrderRequest{
+		Action:            "HOLD",
+		Exchange:          ExchangeNFO,
+		PriceType:         PriceTypeLimit,
+		Product:           ProductCNC,
+		Quantity:          -1,
+		DisclosedQuantity: -1,
+	}
+	err := r.Validate()
+	if !errors.Is(err, ErrInvalidParameter) {
+		t.Fatalf("Validate() = %v, want an error matching ErrInvalidParameter", err)
+	}
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate() = %T, want *ValidationError", err)
+	}
+
+	var fields []string
+	for _, fe := range verr.Errors {
+		fields = append(fields, fe.Field)
+	}
+	want := []string{"symbol", "action", "product", "disclosed_quantity", "price", "quantity"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("invalid fields = %v, want %v", fields, want)
+	}
+	for _, fe := range verr.Errors {
+		if !strings.Contains(err.Error(), fe.Error()) {
+			t.Errorf("error %q does not mention %q", err, fe)
+		}
+	}
+}
+
+func TestOptionalQuantityParams(t *testing.T) {
+	var (
+		requests int
+		payload  map[string]interface{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		payload = nil
+		json.NewDecoder(r.Body).Decode(&payload)
+		fmt.Fprint(w, `{"status":"success","orderid":"1"}`)
+	}))
+	defer srv.Close()
+	c := NewClient("test-key", srv.URL)
+
+	methods := []struct {
+		name  string
+		order func(params map[string]interface{}) error
+	}{
+		{name: "PlaceOrder", order: func(params map[string]interface{}) error {
+			_, err := c.PlaceOrder("S1", "SBIN", "BUY", "NSE", "LIMIT", "MIS", 10, params)
+			return err
+		}},
+		{name: "PlaceSmartOrder", order: func(params map[string]interface{}) error {
+			_, err := c.PlaceSmartOrder("S1", "SBIN", "BUY", "NSE", "LIMIT", "MIS", 10, 10, params)
+			return err
+		}},
+		{name: "SplitOrder", order: func(params map[string]interface{}) error {
+			_, err := c.SplitOrder("S1", "SBIN", "NSE", "BUY", 10, 5, "LIMIT", "MIS", params)
+			return err
+		}},
+	}
+
+	for _, m := range methods {
+		t.Run(m.name, func(t *testing.T) {
+			for _, key := range []string{"disclosed_quantity", "quantity"} {
+				requests = 0
+				err := m.order(map[string]interface{}{key: 2.6, "price": 820.5})
+				if !errors.Is(err, ErrInvalidParameter) || !strings.Contains(err.Error(), key) {
+					t.Errorf("%s with %s 2.6 = %v, want an ErrInvalidParameter naming %s", m.name, key, err, key)
+				}
+				if requests != 0 {
+					t.Errorf("%s with %s 2.6 sent %d requests, want none", m.name, key, requests)
+				}
+			}
+
+			if err := m.order(map[string]interface{}{"disclosed_quantity": 2.0, "price": 820.5}); err != nil {
+				t.Fatalf("%s: %v", m.name, err)
+			}
+			if payload["disclosed_quantity"] != "2" || payload["price"] != "820.5" {
+				t.Errorf("payload disclosed_quantity = %v, price = %v; want \"2\" and \"820.5\"", payload["disclosed_quantity"], payload["price"])
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package openalgo
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultLatencyBuckets are the histogram buckets, in seconds, used for
+// request latency and tick handling time
+var defaultLatencyBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// PrometheusMetrics is a MetricsCollector that serves its measurements in
+// the Prometheus text exposition format. It is an http.Handler, to be
+// mounted on the application's own server:
+//
+//	metrics := openalgo.NewPrometheusMetrics()
+//	client, _ := openalgo.NewClientWithOptions(apiKey, host, openalgo.WithMetrics(metrics))
+//	http.Handle("/metrics", metrics)
+//
+// It exposes:
+//
+//	openalgo_requests_total{endpoint,result}
+//	openalgo_request_duration_seconds{endpoint}
+//	openalgo_websocket_reconnects_total{result}
+//	openalgo_ticks_total{exchange,symbol,mode}
+//	openalgo_tick_handling_seconds{mode}
+//	openalgo_subscription_messages_total{action,mode,status}
+//
+// Order throughput is the rate of openalgo_requests_total for the order
+// endpoints, such as endpoint="placeorder".
+type PrometheusMetrics struct {
+	mu               sync.Mutex
+	requests         map[string]uint64 // by endpoint and result
+	requestDurations map[string]*histogram
+	reconnects       map[string]uint64 // by result
+	ticks            map[string]uint64 // by exchange, symbol and mode
+	tickHandling     map[string]*histogram
+	subscriptions    map[string]uint64 // by action, mode and status
+}
+
+// NewPrometheusMetrics returns an empty PrometheusMetrics
+func NewPrometheusMetrics() *PrometheusMetrics {
+	return &PrometheusMetrics{
+		requests:         make(map[string]uint64),
+		requestDurations: make(map[string]*histogram),
+		reconnects:       make(map[string]uint64),
+		ticks:            make(map[string]uint64),
+		tickHandling:     make(map[string]*histogram),
+		subscriptions:    make(map[string]uint64),
+	}
+}
+
+// ObserveRequest implements MetricsCollector
+func (m *PrometheusMetrics) ObserveRequest(endpoint string, latency time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[labels("endpoint", endpoint, "result", errorType(err))]++
+	observe(m.requestDurations, labels("endpoint", endpoint), latency)
+}
+
+// ObserveReconnect implements MetricsCollector
+func (m *PrometheusMetrics) ObserveReconnect(attempt int, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reconnects[labels("result", result)]++
+}
+
+// ObserveTick implements MetricsCollector
+func (m *PrometheusMetrics) ObserveTick(instrument Instrument, mode Mode, handling time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ticks[labels("exchange", instrument.Exchange, "symbol", instrument.Symbol, "mode", mode.String())]++
+	observe(m.tickHandling, labels("mode", mode.String()), handling)
+}
+
+// ObserveSubscription implements MetricsCollector
+func (m *PrometheusMetrics) ObserveSubscription(action string, mode Mode, status AckStatus) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subscriptions[labels("action", action, "mode", mode.String(), "status", status.String())]++
+}
+
+// ServeHTTP writes every metric in the Prometheus text exposition format
+func (m *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// WriteTo writes every metric in the Prometheus text exposition format to w
+func (m *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	m.mu.Lock()
+	writeCounter(&b, "openalgo_requests_total", "REST request attempts by endpoint and result.", m.requests)
+	writeHistogram(&b, "openalgo_request_duration_seconds", "REST request latency by endpoint.", m.requestDurations)
+	writeCounter(&b, "openalgo_websocket_reconnects_total", "WebSocket reconnection attempts by result.", m.reconnects)
+	writeCounter(&b, "openalgo_ticks_total", "Market data messages by instrument and mode.", m.ticks)
+	writeHistogram(&b, "openalgo_tick_handling_seconds", "Time spent in tick handlers by mode.", m.tickHandling)
+	writeCounter(&b, "openalgo_subscription_messages_total", "Subscription messages by action, mode and acknowledgement status.", m.subscriptions)
+	m.mu.Unlock()
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// histogram is a cumulative Prometheus histogram over defaultLatencyBuckets
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// observe adds d to the histogram of key in hs, creating it if needed
+func observe(hs map[string]*histogram, key string, d time.Duration) {
+	h, ok := hs[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(defaultLatencyBuckets))}
+		hs[key] = h
+	}
+	v := d.Seconds()
+	for i, bound := range defaultLatencyBuckets {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// labels formats alternating label names and values as a Prometheus label
+// set, without the enclosing braces
+func labels(kv ...string) string {
+	var b strings.Builder
+	for i := 0; i+1 < len(kv); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(kv[i])
+		b.WriteString(`="`)
+		b.WriteString(escapeLabel(kv[i+1]))
+		b.WriteByte('"')
+	}
+	return b.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeCounter(b *strings.Builder, name, help string, values map[string]uint64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	for _, key := range sortedKeys(values) {
+		fmt.Fprintf(b, "%s{%s} %d\n", name, key, values[key])
+	}
+}
+
+func writeHistogram(b *strings.Builder, name, help string, values map[string]*histogram) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	for _, key := range sortedKeys(values) {
+		h := values[key]
+		for i, bound := range defaultLatencyBuckets {
+			fmt.Fprintf(b, "%s_bucket{%s,le=\"%s\"} %d\n", name, key, strconv.FormatFloat(bound, 'g', -1, 64), h.counts[i])
+		}
+		fmt.Fprintf(b, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, key, h.count)
+		fmt.Fprintf(b, "%s_sum{%s} %s\n", name, key, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(b, "%s_count{%s} %d\n", name, key, h.count)
+	}
+}
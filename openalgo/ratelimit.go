@@ -0,0 +1,140 @@
+package openalgo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// dataEndpoints lists the market data endpoints sharing the data budget.
+// Order endpoints share the order budget, see orderEndpoints.
+var dataEndpoints = map[string]bool{
+	"quotes":  true,
+	"depth":   true,
+	"history": true,
+}
+
+// RateLimit is a token-bucket budget: Rate requests per second on average,
+// with bursts of up to Burst requests. A zero Rate disables the limit.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimits holds the client-side budgets applied before requests are sent
+type RateLimits struct {
+	// Orders covers placeorder, placesmartorder, modifyorder, cancelorder,
+	// cancelallorder, closeposition, basketorder and splitorder
+	Orders RateLimit
+	// Data covers quotes, depth and history
+	Data RateLimit
+	// Subscriptions paces WebSocket subscribe and unsubscribe messages
+	Subscriptions RateLimit
+}
+
+// DefaultRateLimits returns the budgets matching the default limits of an
+// OpenAlgo server: 10 order requests and 50 data requests per second, and 50
+// WebSocket subscription messages per second
+func DefaultRateLimits() RateLimits {
+	return RateLimits{
+		Orders:        RateLimit{Rate: 10, Burst: 10},
+		Data:          RateLimit{Rate: 50, Burst: 50},
+		Subscriptions: RateLimit{Rate: 50, Burst: 50},
+	}
+}
+
+func (l RateLimit) validate(name string) error {
+	if l.Rate < 0 {
+		return fmt.Errorf("%s rate limit must not be negative, got %g", name, l.Rate)
+	}
+	if l.Rate > 0 && l.Burst < 1 {
+		return fmt.Errorf("%s rate limit burst must be at least 1, got %d", name, l.Burst)
+	}
+	return nil
+}
+
+// rateLimiter holds one token bucket per endpoint class
+type rateLimiter struct {
+	orders        *tokenBucket
+	data          *tokenBucket
+	subscriptions *tokenBucket
+}
+
+func newRateLimiter(limits RateLimits) *rateLimiter {
+	return &rateLimiter{
+		orders:        newTokenBucket(limits.Orders),
+		data:          newTokenBucket(limits.Data),
+		subscriptions: newTokenBucket(limits.Subscriptions),
+	}
+}
+
+// wait blocks until a request to endpoint may be sent or ctx is done
+func (r *rateLimiter) wait(ctx context.Context, endpoint string) error {
+	if r == nil {
+		return nil
+	}
+	switch {
+	case orderEndpoints[endpoint]:
+		return r.orders.wait(ctx)
+	case dataEndpoints[endpoint]:
+		return r.data.wait(ctx)
+	}
+	return nil
+}
+
+// tokenBucket is a minimal token-bucket limiter. A nil bucket never blocks.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	if limit.Rate <= 0 {
+		return nil
+	}
+	return &tokenBucket{
+		rate:   limit.Rate,
+		burst:  float64(limit.Burst),
+		tokens: float64(limit.Burst),
+		last:   time.Now(),
+	}
+}
+
+// wait takes a token, sleeping until one is available. The token is handed
+// back if ctx is done before then.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	b.tokens--
+	deficit := -b.tokens
+	b.mu.Unlock()
+
+	if deficit <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(time.Duration(deficit / b.rate * float64(time.Second)))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,187 @@
+package openalgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// readOnlyEndpoints lists the endpoints that are safe to retry because they
+// do not change any state on the broker side
+var readOnlyEndpoints = map[string]bool{
+	"ping":         true,
+	"analyzer":     true,
+	"funds":        true,
+	"orderbook":    true,
+	"tradebook":    true,
+	"positionbook": true,
+	"holdings":     true,
+	"orderstatus":  true,
+	"openposition": true,
+	"quotes":       true,
+	"depth":        true,
+	"history":      true,
+	"intervals":    true,
+	"symbol":       true,
+	"search":       true,
+	"expiry":       true,
+}
+
+// RetryPolicy controls how REST calls that fail with a transient error are
+// retried. Read-only endpoints are retried automatically; endpoints that
+// change state, such as placeorder, are only retried when RetryOrders is set.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one.
+	// A value of 0 or 1 disables retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay computed from the backoff schedule. A call
+	// whose Retry-After asks for a longer wait is not retried.
+	MaxBackoff time.Duration
+	// Multiplier is the factor applied to the delay after every attempt
+	Multiplier float64
+	// Jitter is the fraction, between 0 and 1, by which each delay is
+	// randomly shortened to spread out retries from concurrent callers
+	Jitter float64
+	// RetryableStatusCodes lists the HTTP status codes that are retried
+	RetryableStatusCodes []int
+	// RetryOrders enables retries for order endpoints as well. A retried
+	// order may be placed twice if the first attempt reached the broker.
+	RetryOrders bool
+}
+
+// DefaultRetryPolicy returns the policy used when none is configured: three
+// attempts with exponential backoff starting at 200ms, retrying network
+// errors, 429 and 5xx responses on read-only endpoints
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// transportError marks failures that happened before a response was received
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return fmt.Sprintf("request failed: %v", e.err)
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
+
+func (p RetryPolicy) validate() error {
+	if p.MaxAttempts < 0 {
+		return fmt.Errorf("retry max attempts must not be negative, got %d", p.MaxAttempts)
+	}
+	if p.InitialBackoff < 0 || p.MaxBackoff < 0 {
+		return fmt.Errorf("retry backoff must not be negative")
+	}
+	if p.Multiplier != 0 && p.Multiplier < 1 {
+		return fmt.Errorf("retry multiplier must be at least 1, got %g", p.Multiplier)
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return fmt.Errorf("retry jitter must be between 0 and 1, got %g", p.Jitter)
+	}
+	return nil
+}
+
+// appliesTo reports whether calls to endpoint may be retried
+func (p RetryPolicy) appliesTo(endpoint string) bool {
+	if p.MaxAttempts <= 1 {
+		return false
+	}
+	return readOnlyEndpoints[endpoint] || p.RetryOrders
+}
+
+// isRetryable reports whether err is a transient failure worth retrying
+func (p RetryPolicy) isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		for _, code := range p.RetryableStatusCodes {
+			if apiErr.HTTPStatus == code {
+				return true
+			}
+		}
+		return false
+	}
+
+	var tErr *transportError
+	return errors.As(err, &tErr)
+}
+
+// backoff returns the delay before the retry following attempt. A positive
+// retryAfter sent by the server takes precedence when it is longer. ok is
+// false when retryAfter exceeds MaxBackoff, in which case the call should not
+// be retried.
+func (p RetryPolicy) backoff(attempt int, retryAfter time.Duration) (delay time.Duration, ok bool) {
+	if p.MaxBackoff > 0 && retryAfter > p.MaxBackoff {
+		return 0, false
+	}
+	delay = backoffDelay(attempt, p.InitialBackoff, p.MaxBackoff, p.Multiplier, p.Jitter)
+	if retryAfter > delay {
+		delay = retryAfter
+	}
+	return delay, true
+}
+
+// backoffDelay computes an exponential backoff delay for the given attempt,
+// capped at max and randomly shortened by up to jitter of its length
+func backoffDelay(attempt int, initial, max time.Duration, multiplier, jitter float64) time.Duration {
+	if multiplier == 0 {
+		multiplier = 2
+	}
+	delay := initial
+	for i := 1; i < attempt; i++ {
+		delay = time.Duration(float64(delay) * multiplier)
+		if max > 0 && delay >= max {
+			break
+		}
+	}
+	if max > 0 && delay > max {
+		delay = max
+	}
+	if jitter > 0 && delay > 0 {
+		delay -= time.Duration(rand.Float64() * jitter * float64(delay))
+	}
+	return delay
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as
+// an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
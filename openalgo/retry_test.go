@@ -0,0 +1,91 @@
+package openalgo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newRetryAfterServer returns a REST server that answers every request with
+// a 429 carrying retryAfter, counting the requests in calls
+func newRetryAfterServer(t *testing.T, retryAfter string, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", retryAfter)
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"status":"error","message":"rate limited"}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRetryAfterBeyondMaxBackoff(t *testing.T) {
+	var calls atomic.Int32
+	srv := newRetryAfterServer(t, "3600", &calls)
+	c := NewClient("test-key", srv.URL)
+
+	start := time.Now()
+	_, err := c.Funds()
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Funds took %s, want it to fail without waiting for Retry-After", elapsed)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatus != http.StatusTooManyRequests {
+		t.Fatalf("Funds = %v, want the 429 *APIError", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("server got %d requests, want 1", got)
+	}
+}
+
+func TestRetryAfterWithinMaxBackoff(t *testing.T) {
+	var calls atomic.Int32
+	srv := newRetryAfterServer(t, "1", &calls)
+	policy := DefaultRetryPolicy()
+	policy.MaxAttempts = 2
+	c, err := NewClientWithOptions("test-key", srv.URL, WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatalf("NewClientWithOptions: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := c.FundsCtx(context.Background()); err == nil {
+		t.Fatal("FundsCtx succeeded, want an error")
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("FundsCtx took %s, want it to wait the 1s Retry-After", elapsed)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("server got %d requests, want 2", got)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+	tests := []struct {
+		attempt    int
+		retryAfter time.Duration
+		want       time.Duration
+		wantOK     bool
+	}{
+		{attempt: 1, want: 100 * time.Millisecond, wantOK: true},
+		{attempt: 3, want: 400 * time.Millisecond, wantOK: true},
+		{attempt: 10, want: time.Second, wantOK: true},
+		{attempt: 1, retryAfter: 500 * time.Millisecond, want: 500 * time.Millisecond, wantOK: true},
+		{attempt: 3, retryAfter: 200 * time.Millisecond, want: 400 * time.Millisecond, wantOK: true},
+		{attempt: 1, retryAfter: time.Second, want: time.Second, wantOK: true},
+		{attempt: 1, retryAfter: time.Hour, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := p.backoff(tt.attempt, tt.retryAfter)
+		if ok != tt.wantOK || (ok && got != tt.want) {
+			t.Errorf("backoff(%d, %s) = %s, %v; want %s, %v", tt.attempt, tt.retryAfter, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package openalgo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy decides what a TickStream does with a tick when its buffer
+// is full
+type OverflowPolicy int
+
+// Overflow policies
+const (
+	// OverflowBlock waits for the consumer, stalling the feed for every
+	// instrument until there is room in the buffer
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest buffered tick
+	OverflowDropOldest
+	// OverflowDropNewest discards the incoming tick
+	OverflowDropNewest
+	// OverflowCoalesce keeps only the latest tick per instrument, replacing
+	// a buffered tick of the same instrument in place
+	OverflowCoalesce
+)
+
+// defaultStreamBuffer is the buffer size used when StreamConfig leaves it
+// unset
+const defaultStreamBuffer = 256
+
+// StreamConfig configures a TickStream
+type StreamConfig struct {
+	// BufferSize is the number of ticks buffered between the WebSocket
+	// reader and the consumer, 256 by default
+	BufferSize int
+	// Overflow is the policy applied when the buffer is full
+	Overflow OverflowPolicy
+	// DepthLevels is the number of depth levels subscribed to in ModeDepth,
+	// DefaultDepthLevels by default
+	DepthLevels int
+}
+
+// TickStream delivers the ticks of a subscription on a channel, decoupling
+// slow consumers from the WebSocket reader
+type TickStream struct {
+	// C receives the ticks. It is closed when the stream is closed, its
+	// context is done or the client disconnects.
+	C <-chan Tick
+
+	out    chan Tick
+	sub    *Subscription
+	policy OverflowPolicy
+	size   int
+
+	mu     sync.Mutex
+	queue  []Tick
+	latest map[subKey]int // index in queue of the tick of an instrument
+
+	notify chan struct{}
+	space  chan struct{}
+	done   chan struct{}
+	once   sync.Once
+
+	dropped atomic.Uint64
+}
+
+// Stream subscribes to instruments in mode and delivers their ticks on the
+// returned stream's channel until ctx is done, Close is called or the client
+// disconnects
+func (c *Client) Stream(ctx context.Context, instruments []Instrument, mode Mode, cfg StreamConfig) (*TickStream, error) {
+	if cfg.BufferSize < 0 {
+		return nil, fmt.Errorf("%w: stream buffer size must not be negative", ErrInvalidParameter)
+	}
+	if cfg.Overflow < OverflowBlock || cfg.Overflow > OverflowCoalesce {
+		return nil, fmt.Errorf("%w: unknown overflow policy %d", ErrInvalidParameter, cfg.Overflow)
+	}
+	if cfg.BufferSize == 0 {
+		cfg.BufferSize = defaultStreamBuffer
+	}
+
+	out := make(chan Tick)
+	s := &TickStream{
+		C:      out,
+		out:    out,
+		policy: cfg.Overflow,
+		size:   cfg.BufferSize,
+		latest: make(map[subKey]int),
+		notify: make(chan struct{}, 1),
+		space:  make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+
+	sub, err := c.subscribeTicks(ctx, instruments, mode, cfg.DepthLevels, s.push)
+	if err != nil {
+		return nil, err
+	}
+	s.sub = sub
+	if !c.addStream(s) {
+		s.Close()
+		return nil, ErrNotConnected
+	}
+
+	go s.pump()
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.Close()
+		case <-s.done:
+		}
+	}()
+	return s, nil
+}
+
+// Dropped returns the number of ticks discarded by the overflow policy,
+// including ticks replaced by a newer one under OverflowCoalesce
+func (s *TickStream) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Ack returns the server's acknowledgement of the stream's instruments
+func (s *TickStream) Ack() *SubscriptionAck {
+	return s.sub.Ack()
+}
+
+// Close cancels the subscription and closes C. It is safe to call more
+// than once.
+func (s *TickStream) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.done)
+		s.sub.c.removeStream(s)
+		err = s.sub.Unsubscribe()
+	})
+	return err
+}
+
+// addStream registers s to be closed by Disconnect. It reports false when
+// the client is no longer connected.
+func (c *Client) addStream(s *TickStream) bool {
+	c.ws.mu.Lock()
+	defer c.ws.mu.Unlock()
+	if c.ws.conn == nil {
+		return false
+	}
+	if c.ws.streams == nil {
+		c.ws.streams = make(map[*TickStream]bool)
+	}
+	c.ws.streams[s] = true
+	return true
+}
+
+// removeStream forgets a closed stream
+func (c *Client) removeStream(s *TickStream) {
+	c.ws.mu.Lock()
+	defer c.ws.mu.Unlock()
+	delete(c.ws.streams, s)
+}
+
+// push buffers t according to the overflow policy. It is called by the
+// WebSocket reader.
+func (s *TickStream) push(t Tick) {
+	for {
+		s.mu.Lock()
+		if s.policy == OverflowCoalesce {
+			h := t.Header()
+			key := subKey{exchange: h.Exchange, symbol: h.Symbol, mode: h.Mode}
+			if i, ok := s.latest[key]; ok {
+				s.queue[i] = t
+				s.mu.Unlock()
+				s.dropped.Add(1)
+				return
+			}
+		}
+
+		if len(s.queue) >= s.size {
+			switch s.policy {
+			case OverflowBlock:
+				s.mu.Unlock()
+				select {
+				case <-s.space:
+					continue
+				case <-s.done:
+					return
+				}
+			case OverflowDropNewest:
+				s.mu.Unlock()
+				s.dropped.Add(1)
+				return
+			default:
+				s.popLocked()
+				s.dropped.Add(1)
+			}
+		}
+
+		if s.policy == OverflowCoalesce {
+			h := t.Header()
+			s.latest[subKey{exchange: h.Exchange, symbol: h.Symbol, mode: h.Mode}] = len(s.queue)
+		}
+		s.queue = append(s.queue, t)
+		s.mu.Unlock()
+
+		select {
+		case s.notify <- struct{}{}:
+		default:
+		}
+		return
+	}
+}
+
+// popLocked removes and returns the oldest buffered tick. s.mu must be held.
+func (s *TickStream) popLocked() Tick {
+	t := s.queue[0]
+	s.queue[0] = nil
+	s.queue = s.queue[1:]
+	if s.policy == OverflowCoalesce {
+		h := t.Header()
+		delete(s.latest, subKey{exchange: h.Exchange, symbol: h.Symbol, mode: h.Mode})
+		for key, i := range s.latest {
+			s.latest[key] = i - 1
+		}
+	}
+	return t
+}
+
+// pump moves buffered ticks to the consumer until the stream is closed
+func (s *TickStream) pump() {
+	defer close(s.out)
+	for {
+		s.mu.Lock()
+		var t Tick
+		if len(s.queue) > 0 {
+			t = s.popLocked()
+		}
+		s.mu.Unlock()
+
+		if t == nil {
+			select {
+			case <-s.notify:
+				continue
+			case <-s.done:
+				return
+			}
+		}
+
+		select {
+		case s.space <- struct{}{}:
+		default:
+		}
+
+		select {
+		case s.out <- t:
+		case <-s.done:
+			return
+		}
+	}
+}
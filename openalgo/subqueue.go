@@ -0,0 +1,494 @@
+package openalgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AckStatus is the server's answer to the subscription of an instrument
+type AckStatus int
+
+// Acknowledgement statuses
+const (
+	// AckPending means no answer has been received yet
+	AckPending AckStatus = iota
+	// AckAccepted means the server accepted the subscription
+	AckAccepted
+	// AckRejected means the server refused the subscription
+	AckRejected
+	// AckUnknown means no answer will arrive: the request could not be
+	// sent, its connection was lost or the ack timeout passed
+	AckUnknown
+)
+
+// String returns the name of s
+func (s AckStatus) String() string {
+	switch s {
+	case AckPending:
+		return "pending"
+	case AckAccepted:
+		return "accepted"
+	case AckRejected:
+		return "rejected"
+	case AckUnknown:
+		return "unknown"
+	}
+	return fmt.Sprintf("AckStatus(%d)", int(s))
+}
+
+// SubscriptionResult is the outcome of subscribing one instrument
+type SubscriptionResult struct {
+	Instrument Instrument
+	Mode       Mode
+	Status     AckStatus
+	// Err is an *APIError when the server rejected the subscription, the
+	// reason of an AckUnknown status, or ErrDepthUnavailable when an
+	// accepted depth subscription has fewer levels than requested
+	Err error
+}
+
+// SubscriptionAck tracks the server's acknowledgement of the instruments of
+// a subscribe call. Subscribe calls return as soon as their messages are
+// queued; the ack resolves once every instrument has been accepted or
+// rejected.
+type SubscriptionAck struct {
+	done     chan struct{}
+	mu       sync.Mutex
+	results  []SubscriptionResult
+	resolved []bool
+	pending  int
+	// onDone are called once every instrument is resolved
+	onDone []func()
+}
+
+// newSubscriptionAck returns an ack for keys. Keys that are not pending are
+// resolved as accepted straight away.
+func newSubscriptionAck(keys []subKey, pending map[subKey]bool) *SubscriptionAck {
+	a := &SubscriptionAck{
+		done:     make(chan struct{}),
+		results:  make([]SubscriptionResult, len(keys)),
+		resolved: make([]bool, len(keys)),
+	}
+	for i, key := range keys {
+		a.results[i] = SubscriptionResult{Instrument: key.instrument(), Mode: key.mode}
+		if pending[key] {
+			a.pending++
+		} else {
+			a.results[i].Status = AckAccepted
+			a.resolved[i] = true
+		}
+	}
+	if a.pending == 0 {
+		close(a.done)
+	}
+	return a
+}
+
+// resolve records the outcome of instrument i. Later outcomes for the same
+// instrument are ignored.
+func (a *SubscriptionAck) resolve(i int, status AckStatus, err error) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	if a.resolved[i] {
+		a.mu.Unlock()
+		return
+	}
+	a.resolved[i] = true
+	a.results[i].Status = status
+	a.results[i].Err = err
+	a.pending--
+	var onDone []func()
+	if a.pending == 0 {
+		close(a.done)
+		onDone, a.onDone = a.onDone, nil
+	}
+	a.mu.Unlock()
+
+	for _, fn := range onDone {
+		fn()
+	}
+}
+
+// whenDone calls fn once every instrument is resolved, straight away if
+// that is already the case. fn runs on the goroutine resolving the last
+// instrument and must not block.
+func (a *SubscriptionAck) whenDone(fn func()) {
+	a.mu.Lock()
+	select {
+	case <-a.done:
+		a.mu.Unlock()
+		fn()
+		return
+	default:
+	}
+	a.onDone = append(a.onDone, fn)
+	a.mu.Unlock()
+}
+
+// Done returns a channel that is closed once every instrument is resolved
+func (a *SubscriptionAck) Done() <-chan struct{} {
+	return a.done
+}
+
+// Results returns the outcome of every instrument, listing an instrument
+// given more than once a single time. Instruments still awaiting their
+// acknowledgement have the AckPending status.
+func (a *SubscriptionAck) Results() []SubscriptionResult {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]SubscriptionResult(nil), a.results...)
+}
+
+// Wait blocks until every instrument is resolved or ctx is done. It returns
+// ctx's error, or an error joining the failure of every instrument that was
+// not accepted in full.
+func (a *SubscriptionAck) Wait(ctx context.Context) error {
+	select {
+	case <-a.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return a.failures()
+}
+
+// failures returns an error joining the failure of every instrument that
+// was not accepted in full, nil if there is none
+func (a *SubscriptionAck) failures() error {
+	var errs []error
+	results := a.Results()
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("%s:%s %s %s: %w", r.Instrument.Exchange, r.Instrument.Symbol, r.Mode, r.Status, r.Err))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("failed to subscribe to %d of %d instruments: %w", len(errs), len(results), errors.Join(errs...))
+}
+
+// queuedMessage is a subscribe or unsubscribe message waiting in the
+// subscription queue, with the ack slot it resolves
+type queuedMessage struct {
+	msg   SubscriptionMessage
+	ack   *SubscriptionAck
+	index int
+}
+
+// subQueue is the unbounded FIFO of subscription messages drained by the
+// subscription sender
+type subQueue struct {
+	mu     sync.Mutex
+	items  []queuedMessage
+	notify chan struct{}
+}
+
+func (q *subQueue) push(items ...queuedMessage) {
+	q.mu.Lock()
+	q.items = append(q.items, items...)
+	q.mu.Unlock()
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (q *subQueue) pop() (queuedMessage, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) == 0 {
+		return queuedMessage{}, false
+	}
+	item := q.items[0]
+	q.items[0] = queuedMessage{}
+	q.items = q.items[1:]
+	return item, true
+}
+
+// drain empties the queue and returns its items
+func (q *subQueue) drain() []queuedMessage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	items := q.items
+	q.items = nil
+	return items
+}
+
+// ackKey correlates a server status reply with the message it answers
+type ackKey struct {
+	action   string
+	exchange string
+	symbol   string
+}
+
+// pendingAck is a message sent on conn and awaiting its status reply
+type pendingAck struct {
+	id   uint64
+	mode Mode
+	// depth is the number of depth levels requested
+	depth int
+	conn  *wsConn
+	ack   *SubscriptionAck
+	index int
+}
+
+// sendSubscriptions writes queued subscription messages to the live
+// connection, paced by the subscription rate limit, until closed is closed
+// by Disconnect
+func (c *Client) sendSubscriptions(closed chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-closed:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		item, ok := c.ws.queue.pop()
+		if !ok {
+			select {
+			case <-c.ws.queue.notify:
+				continue
+			case <-closed:
+				return
+			}
+		}
+
+		if err := c.limiter.subscriptions.wait(ctx); err != nil {
+			c.resolveSubscription(item, AckUnknown, ErrNotConnected)
+			return
+		}
+		c.sendSubscription(item)
+	}
+}
+
+// sendSubscription writes item on the live connection, registering it to
+// receive the server's status reply
+func (c *Client) sendSubscription(item queuedMessage) {
+	msg := item.msg
+	key := ackKey{action: msg.Action, exchange: msg.Exchange, symbol: msg.Symbol}
+
+	c.ws.mu.Lock()
+	conn := c.ws.conn
+	if conn == nil {
+		c.ws.mu.Unlock()
+		c.resolveSubscription(item, AckUnknown, ErrNotConnected)
+		return
+	}
+	if c.ws.acks == nil {
+		c.ws.acks = make(map[ackKey][]pendingAck)
+	}
+	// Registered before the write, as the reply may arrive before the
+	// write returns
+	c.ws.nextID++
+	id := c.ws.nextID
+	c.ws.acks[key] = append(c.ws.acks[key], pendingAck{id: id, mode: Mode(msg.Mode), depth: msg.Depth, conn: conn, ack: item.ack, index: item.index})
+	c.ws.mu.Unlock()
+
+	c.logger.Debug("sending subscription message", "action", msg.Action, "exchange", msg.Exchange, "symbol", msg.Symbol, "mode", Mode(msg.Mode).String())
+	if err := conn.write(msg); err != nil {
+		if p, ok := c.removePendingAck(key, id); ok {
+			c.resolvePending(key.action, p, AckUnknown, fmt.Errorf("error sending %s for %s:%s: %w", msg.Action, msg.Exchange, msg.Symbol, err))
+		}
+		return
+	}
+	if c.ackTimeout > 0 {
+		time.AfterFunc(c.ackTimeout, func() {
+			if p, ok := c.removePendingAck(key, id); ok {
+				c.resolvePending(key.action, p, AckUnknown, ErrNoAcknowledgement)
+			}
+		})
+	}
+}
+
+// removePendingAck removes and returns the message id awaiting a reply for
+// key, if it is still waiting
+func (c *Client) removePendingAck(key ackKey, id uint64) (pendingAck, bool) {
+	c.ws.mu.Lock()
+	defer c.ws.mu.Unlock()
+	pending := c.ws.acks[key]
+	for i, p := range pending {
+		if p.id != id {
+			continue
+		}
+		pending = append(pending[:i:i], pending[i+1:]...)
+		if len(pending) == 0 {
+			delete(c.ws.acks, key)
+		} else {
+			c.ws.acks[key] = pending
+		}
+		return p, true
+	}
+	return pendingAck{}, false
+}
+
+// takePendingAck removes and returns the oldest message awaiting a reply for
+// key, preferring one in mode when mode is known
+func (c *Client) takePendingAck(key ackKey, mode Mode) (pendingAck, bool) {
+	c.ws.mu.Lock()
+	defer c.ws.mu.Unlock()
+	pending := c.ws.acks[key]
+	if len(pending) == 0 {
+		return pendingAck{}, false
+	}
+	i := 0
+	if mode != 0 {
+		for j, p := range pending {
+			if p.mode == mode {
+				i = j
+				break
+			}
+		}
+	}
+	p := pending[i]
+	pending = append(pending[:i:i], pending[i+1:]...)
+	if len(pending) == 0 {
+		delete(c.ws.acks, key)
+	} else {
+		c.ws.acks[key] = pending
+	}
+	return p, true
+}
+
+// failPendingAcks resolves every message awaiting a reply on conn, or on any
+// connection when conn is nil, with err
+func (c *Client) failPendingAcks(conn *wsConn, err error) {
+	type failedAck struct {
+		action string
+		p      pendingAck
+	}
+	var failed []failedAck
+	c.ws.mu.Lock()
+	for key, pending := range c.ws.acks {
+		kept := pending[:0]
+		for _, p := range pending {
+			if conn == nil || p.conn == conn {
+				failed = append(failed, failedAck{action: key.action, p: p})
+			} else {
+				kept = append(kept, p)
+			}
+		}
+		if len(kept) == 0 {
+			delete(c.ws.acks, key)
+		} else {
+			c.ws.acks[key] = kept
+		}
+	}
+	c.ws.mu.Unlock()
+
+	for _, f := range failed {
+		c.resolvePending(f.action, f.p, AckUnknown, err)
+	}
+}
+
+// resolveSubscription records the outcome of a queued message that was
+// never sent and resolves its ack slot
+func (c *Client) resolveSubscription(item queuedMessage, status AckStatus, err error) {
+	c.metrics.ObserveSubscription(item.msg.Action, Mode(item.msg.Mode), status)
+	item.ack.resolve(item.index, status, err)
+}
+
+// resolvePending records the outcome of a sent message and resolves its ack
+// slot
+func (c *Client) resolvePending(action string, p pendingAck, status AckStatus, err error) {
+	c.metrics.ObserveSubscription(action, p.mode, status)
+	p.ack.resolve(p.index, status, err)
+}
+
+// subscriptionReply is a status message answering subscribe or unsubscribe
+// messages
+type subscriptionReply struct {
+	Type          string               `json:"type"`
+	Status        string               `json:"status"`
+	Message       string               `json:"message"`
+	Subscriptions []subscriptionStatus `json:"subscriptions"`
+	Successful    []subscriptionStatus `json:"successful"`
+	Failed        []subscriptionStatus `json:"failed"`
+}
+
+// subscriptionStatus is the outcome of one instrument in a subscriptionReply
+type subscriptionStatus struct {
+	Symbol   string          `json:"symbol"`
+	Exchange string          `json:"exchange"`
+	Status   string          `json:"status"`
+	Message  string          `json:"message"`
+	Mode     json.RawMessage `json:"mode"`
+	// Depth is the number of depth levels subscribed to, ActualDepth the
+	// number the broker provides when it falls back to fewer levels
+	Depth       FlexInt `json:"depth"`
+	ActualDepth FlexInt `json:"actual_depth"`
+}
+
+// handleSubscriptionReply resolves the messages answered by a subscribe or
+// unsubscribe status reply
+func (c *Client) handleSubscriptionReply(reply subscriptionReply, raw []byte) {
+	resolve := func(s subscriptionStatus, accepted bool) {
+		p, ok := c.takePendingAck(ackKey{action: reply.Type, exchange: s.Exchange, symbol: s.Symbol}, parseReplyMode(s.Mode))
+
+		status := AckAccepted
+		var err error
+		if !accepted {
+			status = AckRejected
+			message := s.Message
+			if message == "" {
+				message = reply.Message
+			}
+			err = newAPIError("websocket", 0, "error", message, raw)
+		} else if ok && p.mode == ModeDepth && reply.Type == "subscribe" {
+			levels := s.ActualDepth.Int()
+			if levels == 0 {
+				levels = s.Depth.Int()
+			}
+			if levels > 0 && levels < p.depth {
+				err = fmt.Errorf("%w: requested %d levels, server provides %d", ErrDepthUnavailable, p.depth, levels)
+			}
+		}
+
+		if err != nil {
+			c.logger.Warn("subscription not accepted", "action", reply.Type, "exchange", s.Exchange, "symbol", s.Symbol, "error", err)
+		}
+		if ok {
+			c.resolvePending(reply.Type, p, status, err)
+		}
+	}
+
+	for _, s := range reply.Subscriptions {
+		resolve(s, s.Status == "" || s.Status == "success")
+	}
+	for _, s := range reply.Successful {
+		resolve(s, s.Status == "" || s.Status == "success")
+	}
+	for _, s := range reply.Failed {
+		resolve(s, false)
+	}
+
+	if reply.Status == "error" && len(reply.Subscriptions)+len(reply.Successful)+len(reply.Failed) == 0 {
+		c.logger.Error("websocket error", "action", reply.Type, "error", newAPIError("websocket", 0, reply.Status, reply.Message, raw))
+	}
+}
+
+// parseReplyMode reads the mode of a status reply, sent either as the mode
+// number or its name. It returns 0 when the mode is absent or unknown.
+func parseReplyMode(raw json.RawMessage) Mode {
+	text := strings.Trim(strings.TrimSpace(string(raw)), `"`)
+	if n, err := strconv.Atoi(text); err == nil {
+		return Mode(n)
+	}
+	for _, m := range []Mode{ModeLTP, ModeQuote, ModeDepth} {
+		if strings.EqualFold(text, m.String()) {
+			return m
+		}
+	}
+	return 0
+}
@@ -0,0 +1,40 @@
+package openalgo
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSubscriptionAckDuplicateInstruments(t *testing.T) {
+	server := newFakeWSServer(t)
+	c := newTestWSClient(t, server, WithAckTimeout(300*time.Millisecond))
+
+	sbin := Instrument{Exchange: "NSE", Symbol: "SBIN"}
+	infy := Instrument{Exchange: "NSE", Symbol: "INFY"}
+	sub, err := c.Subscribe([]Instrument{sbin, infy, sbin}, ModeLTP, func(interface{}) {})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := sub.Ack().Wait(ctx); err != nil {
+		t.Fatalf("Ack().Wait: %v", err)
+	}
+	results := sub.Ack().Results()
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2: %+v", len(results), results)
+	}
+	for i, want := range []Instrument{sbin, infy} {
+		if results[i].Instrument != want || results[i].Status != AckAccepted {
+			t.Errorf("result %d = %+v, want %v accepted", i, results[i], want)
+		}
+	}
+
+	// The duplicate holds no extra reference, so one Unsubscribe releases it
+	sub.Unsubscribe()
+	if subs := c.activeSubscriptions(); len(subs) != 0 {
+		t.Errorf("active subscriptions after Unsubscribe = %v, want none", subs)
+	}
+}
@@ -0,0 +1,480 @@
+package openalgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Mode is a WebSocket subscription mode
+type Mode int
+
+// Subscription modes
+const (
+	ModeLTP   Mode = 1
+	ModeQuote Mode = 2
+	ModeDepth Mode = 3
+)
+
+// String returns the name of m as used in log messages
+func (m Mode) String() string {
+	switch m {
+	case ModeLTP:
+		return "LTP"
+	case ModeQuote:
+		return "Quote"
+	case ModeDepth:
+		return "Depth"
+	}
+	return fmt.Sprintf("Mode(%d)", int(m))
+}
+
+// subKey identifies a server-side subscription
+type subKey struct {
+	exchange string
+	symbol   string
+	mode     Mode
+}
+
+// instrument returns the instrument identified by k
+func (k subKey) instrument() Instrument {
+	return Instrument{Exchange: k.exchange, Symbol: k.symbol}
+}
+
+// subEntry is a server-side subscription together with the handlers
+// interested in it. The server subscription lives as long as at least one
+// handler, or the legacy handler slot, references it.
+type subEntry struct {
+	msg      SubscriptionMessage
+	handlers map[uint64]tickHandler
+	// legacy is set by SubscribeLTP, SubscribeQuote and SubscribeDepth and
+	// cleared by their Unsubscribe counterparts
+	legacy    func(interface{})
+	hasLegacy bool
+	// legacyLevels is the number of depth levels requested by the last
+	// legacy subscribe call, 0 for the default
+	legacyLevels int
+	// legacyTrace carries the span of the last legacy subscribe call
+	legacyTrace context.Context
+	// lastTick is when the last tick arrived, or when the entry was
+	// created, and stale whether OnStale has been called since
+	lastTick time.Time
+	stale    bool
+}
+
+// tickHandler receives the market data of a subscription, either as the raw
+// decoded message or as a typed Tick
+type tickHandler struct {
+	raw   func(interface{})
+	typed func(Tick)
+	// levels is the number of depth levels handed to typed, 0 for all
+	levels int
+	// trace carries the subscribing span that tick handling spans link to,
+	// nil when tracing is disabled
+	trace context.Context
+}
+
+func (e *subEntry) refs() int {
+	n := len(e.handlers)
+	if e.hasLegacy {
+		n++
+	}
+	return n
+}
+
+// depthLevels returns the largest number of depth levels requested by the
+// handlers of e, counting a handler that asked for the default as
+// DefaultDepthLevels
+func (e *subEntry) depthLevels() int {
+	levels := 0
+	want := func(n int) {
+		if n <= 0 {
+			n = DefaultDepthLevels
+		}
+		if n > levels {
+			levels = n
+		}
+	}
+	if e.hasLegacy {
+		want(e.legacyLevels)
+	}
+	for _, h := range e.handlers {
+		want(h.levels)
+	}
+	return levels
+}
+
+// Subscription is a handle to a handler registered with Subscribe. Calling
+// Unsubscribe removes the handler; the server-side subscription of an
+// instrument is only cancelled once its last handler is gone.
+type Subscription struct {
+	c    *Client
+	id   uint64
+	keys []subKey
+	ack  *SubscriptionAck
+	once sync.Once
+}
+
+// Ack returns the server's acknowledgement of the subscription's
+// instruments. Instruments that were already subscribed by another handler
+// are resolved as accepted straight away. A rejected instrument keeps the
+// handler until Unsubscribe is called.
+func (s *Subscription) Ack() *SubscriptionAck {
+	return s.ack
+}
+
+// Unsubscribe removes the handler from every instrument of the
+// subscription, queueing unsubscribe messages for instruments left without
+// handlers. It is safe to call more than once.
+func (s *Subscription) Unsubscribe() error {
+	s.once.Do(func() {
+		s.c.removeHandler(s.id, s.keys)
+	})
+	return nil
+}
+
+// Subscribe registers handler for market data of instruments in the given
+// mode and returns a handle to cancel it. Several handlers may subscribe to
+// the same instrument independently. handler receives the decoded message
+// as a map; use SubscribeLTPFunc, SubscribeQuoteFunc or SubscribeDepthFunc
+// for typed ticks.
+func (c *Client) Subscribe(instruments []Instrument, mode Mode, handler func(interface{})) (*Subscription, error) {
+	if handler == nil {
+		return nil, fmt.Errorf("%w: handler is required", ErrInvalidParameter)
+	}
+	return c.subscribeHandler(context.Background(), instruments, mode, tickHandler{raw: handler})
+}
+
+// subscribeTicks registers a typed handler for instruments in mode. levels
+// is the number of depth levels requested in ModeDepth, 0 for the default.
+func (c *Client) subscribeTicks(ctx context.Context, instruments []Instrument, mode Mode, levels int, handler func(Tick)) (*Subscription, error) {
+	if levels < 0 {
+		return nil, fmt.Errorf("%w: depth levels must not be negative, got %d", ErrInvalidParameter, levels)
+	}
+	if levels == 0 {
+		levels = DefaultDepthLevels
+	}
+	return c.subscribeHandler(ctx, instruments, mode, tickHandler{typed: handler, levels: levels})
+}
+
+// subscribeHandler registers handler under a new subscription handle. The
+// subscribing span is a child of the span in ctx, if any.
+func (c *Client) subscribeHandler(ctx context.Context, instruments []Instrument, mode Mode, handler tickHandler) (*Subscription, error) {
+	if c.currentConn() == nil {
+		return nil, ErrNotConnected
+	}
+
+	c.ws.mu.Lock()
+	c.ws.nextID++
+	id := c.ws.nextID
+	c.ws.mu.Unlock()
+
+	ctx, span := c.startSubscribeSpan(ctx, instruments, mode)
+	if c.tracer != nil {
+		handler.trace = ctx
+	}
+	keys, ack := c.addHandler(instruments, mode, handler.levels, func(e *subEntry) {
+		e.handlers[id] = handler
+	})
+	c.endSubscribeSpan(span, ack)
+	return &Subscription{c: c, id: id, keys: keys, ack: ack}, nil
+}
+
+// subscribeLegacy sets the legacy handler of instruments in mode. A nil
+// handler reuses the last legacy handler registered for the mode. levels is
+// the number of depth levels requested in ModeDepth, 0 for the default.
+func (c *Client) subscribeLegacy(instruments []Instrument, mode Mode, levels int, handler func(interface{})) error {
+	if c.currentConn() == nil {
+		return ErrNotConnected
+	}
+
+	c.ws.mu.Lock()
+	if handler != nil {
+		if c.ws.legacyHandlers == nil {
+			c.ws.legacyHandlers = make(map[Mode]func(interface{}))
+		}
+		c.ws.legacyHandlers[mode] = handler
+	} else {
+		handler = c.ws.legacyHandlers[mode]
+	}
+	c.ws.mu.Unlock()
+
+	ctx, span := c.startSubscribeSpan(context.Background(), instruments, mode)
+	var trace context.Context
+	if c.tracer != nil {
+		trace = ctx
+	}
+	_, ack := c.addHandler(instruments, mode, levels, func(e *subEntry) {
+		e.legacy = handler
+		e.hasLegacy = true
+		e.legacyTrace = trace
+		e.legacyLevels = levels
+	})
+	c.endSubscribeSpan(span, ack)
+	return nil
+}
+
+// unsubscribeLegacy clears the legacy handler of instruments in mode
+func (c *Client) unsubscribeLegacy(instruments []Instrument, mode Mode) error {
+	if c.currentConn() == nil {
+		return ErrNotConnected
+	}
+
+	keys := c.instrumentKeys(instruments, mode)
+	c.releaseKeys(keys, func(e *subEntry) {
+		e.legacy = nil
+		e.hasLegacy = false
+		e.legacyTrace = nil
+		e.legacyLevels = 0
+	})
+	return nil
+}
+
+// instrumentKeys returns the subscription keys of the valid instruments,
+// logging and skipping invalid ones. An instrument listed more than once
+// yields a single key.
+func (c *Client) instrumentKeys(instruments []Instrument, mode Mode) []subKey {
+	keys := make([]subKey, 0, len(instruments))
+	seen := make(map[subKey]bool, len(instruments))
+	for _, instrument := range instruments {
+		symbol := instrument.Symbol
+		exchange := instrument.Exchange
+
+		// Use exchange_token as symbol if symbol is not provided
+		if symbol == "" && instrument.ExchangeToken != "" {
+			symbol = instrument.ExchangeToken
+		}
+
+		if exchange == "" || symbol == "" {
+			c.logger.Warn("skipping invalid instrument", "exchange", instrument.Exchange, "symbol", instrument.Symbol, "mode", mode.String())
+			continue
+		}
+		key := subKey{exchange: exchange, symbol: symbol, mode: mode}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// addHandler applies register to the entry of every instrument, creating
+// entries and queueing subscribe messages for instruments not yet
+// subscribed. In ModeDepth an instrument subscribed with fewer than levels
+// depth levels is subscribed again with levels; its entry records the
+// deeper depth only once the server accepts it. It returns without waiting
+// for the messages to be sent.
+func (c *Client) addHandler(instruments []Instrument, mode Mode, levels int, register func(*subEntry)) ([]subKey, *SubscriptionAck) {
+	keys := c.instrumentKeys(instruments, mode)
+	if levels <= 0 {
+		levels = DefaultDepthLevels
+	}
+
+	c.ws.subMu.Lock()
+	defer c.ws.subMu.Unlock()
+
+	var (
+		msgs     []SubscriptionMessage
+		deepened []int // indexes in keys of entries subscribed again deeper
+	)
+	pending := make(map[subKey]bool)
+	c.ws.mu.Lock()
+	if c.ws.subscriptions == nil {
+		c.ws.subscriptions = make(map[subKey]*subEntry)
+	}
+	for i, key := range keys {
+		entry, ok := c.ws.subscriptions[key]
+		if !ok {
+			entry = &subEntry{
+				msg: SubscriptionMessage{
+					Action:   "subscribe",
+					Symbol:   key.symbol,
+					Exchange: key.exchange,
+					Mode:     int(key.mode),
+					Depth:    levels,
+				},
+				handlers: make(map[uint64]tickHandler),
+				lastTick: time.Now(),
+			}
+			c.ws.subscriptions[key] = entry
+			msgs = append(msgs, entry.msg)
+			pending[key] = true
+		} else if mode == ModeDepth && entry.msg.Depth < levels {
+			msg := entry.msg
+			msg.Depth = levels
+			msgs = append(msgs, msg)
+			pending[key] = true
+			deepened = append(deepened, i)
+		}
+		register(entry)
+	}
+	c.ws.mu.Unlock()
+
+	ack := newSubscriptionAck(keys, pending)
+
+	// Each new instrument is subscribed individually (matching Python SDK),
+	// paced by the subscription queue
+	items := make([]queuedMessage, 0, len(msgs))
+	for i, key := range keys {
+		if pending[key] {
+			items = append(items, queuedMessage{msg: msgs[len(items)], ack: ack, index: i})
+		}
+	}
+	if len(deepened) > 0 {
+		ack.whenDone(func() {
+			c.raiseDepth(keys, deepened, levels, ack.Results())
+		})
+	}
+	c.ws.queue.push(items...)
+	return keys, ack
+}
+
+// raiseDepth records levels as the depth of the entries of keys at indexes
+// whose deeper subscription the server accepted in full
+func (c *Client) raiseDepth(keys []subKey, indexes []int, levels int, results []SubscriptionResult) {
+	c.ws.mu.Lock()
+	defer c.ws.mu.Unlock()
+	for _, i := range indexes {
+		if results[i].Status != AckAccepted || results[i].Err != nil {
+			continue
+		}
+		entry, ok := c.ws.subscriptions[keys[i]]
+		if ok && entry.msg.Depth < levels && entry.depthLevels() >= levels {
+			entry.msg.Depth = levels
+		}
+	}
+}
+
+// removeHandler removes handler id from the entries of keys
+func (c *Client) removeHandler(id uint64, keys []subKey) {
+	c.releaseKeys(keys, func(e *subEntry) {
+		delete(e.handlers, id)
+	})
+}
+
+// releaseKeys applies release to the entry of every key and queues an
+// unsubscribe message for entries left without any handler
+func (c *Client) releaseKeys(keys []subKey, release func(*subEntry)) {
+	c.ws.subMu.Lock()
+	defer c.ws.subMu.Unlock()
+
+	var removed []subKey
+	c.ws.mu.Lock()
+	for _, key := range keys {
+		entry, ok := c.ws.subscriptions[key]
+		if !ok {
+			continue
+		}
+		release(entry)
+		if entry.refs() == 0 {
+			delete(c.ws.subscriptions, key)
+			removed = append(removed, key)
+			continue
+		}
+		// Replays only ask for the depth still wanted by a handler
+		if key.mode == ModeDepth {
+			if levels := entry.depthLevels(); levels < entry.msg.Depth {
+				entry.msg.Depth = levels
+			}
+		}
+	}
+	c.ws.mu.Unlock()
+
+	// Unsubscribe from each instrument individually
+	items := make([]queuedMessage, len(removed))
+	for i, key := range removed {
+		items[i] = queuedMessage{msg: SubscriptionMessage{
+			Action:   "unsubscribe",
+			Symbol:   key.symbol,
+			Exchange: key.exchange,
+			Mode:     int(key.mode),
+		}}
+	}
+	c.ws.queue.push(items...)
+}
+
+// activeSubscriptions returns the subscribe messages of every active
+// subscription, used to restore them after a reconnect
+func (c *Client) activeSubscriptions() []SubscriptionMessage {
+	c.ws.mu.Lock()
+	defer c.ws.mu.Unlock()
+	msgs := make([]SubscriptionMessage, 0, len(c.ws.subscriptions))
+	for _, entry := range c.ws.subscriptions {
+		msgs = append(msgs, entry.msg)
+	}
+	return msgs
+}
+
+// tickReceived records a tick for an instrument and mode at received and
+// returns the handlers registered for it
+func (c *Client) tickReceived(key subKey, received time.Time) []tickHandler {
+	c.ws.mu.Lock()
+	defer c.ws.mu.Unlock()
+	entry, ok := c.ws.subscriptions[key]
+	if !ok {
+		return nil
+	}
+	entry.lastTick = received
+	entry.stale = false
+	handlers := make([]tickHandler, 0, entry.refs())
+	if entry.hasLegacy && entry.legacy != nil {
+		handlers = append(handlers, tickHandler{raw: entry.legacy, trace: entry.legacyTrace})
+	}
+	for _, h := range entry.handlers {
+		handlers = append(handlers, h)
+	}
+	return handlers
+}
+
+// dispatchMarketData delivers a market_data message to the handlers of its
+// instrument, decoding it only into the forms those handlers need. A form
+// that fails to decode is skipped for its handlers, and logged once, without
+// affecting handlers of the other form.
+func (c *Client) dispatchMarketData(md MarketData, raw []byte) {
+	received := time.Now()
+	handlers := c.tickReceived(subKey{exchange: md.Exchange, symbol: md.Symbol, mode: Mode(md.Mode)}, received)
+	if len(handlers) == 0 {
+		return
+	}
+	defer func() {
+		c.metrics.ObserveTick(Instrument{Exchange: md.Exchange, Symbol: md.Symbol}, Mode(md.Mode), time.Since(received))
+	}()
+
+	var (
+		data      map[string]interface{}
+		tick      Tick
+		rawErr    error
+		decodeErr error
+	)
+	for _, h := range handlers {
+		span := c.startTickSpan(h, md)
+		if h.raw != nil {
+			if data == nil && rawErr == nil {
+				if rawErr = json.Unmarshal(raw, &data); rawErr != nil {
+					c.logger.Warn("failed to decode market data", "exchange", md.Exchange, "symbol", md.Symbol, "error", rawErr)
+				}
+			}
+			if rawErr != nil {
+				span.RecordError(rawErr)
+			} else {
+				h.raw(data)
+			}
+		}
+		if h.typed != nil {
+			if tick == nil && decodeErr == nil {
+				if tick, decodeErr = decodeTick(md, received); decodeErr != nil {
+					c.logger.Warn("failed to decode tick", "exchange", md.Exchange, "symbol", md.Symbol, "mode", Mode(md.Mode).String(), "error", decodeErr)
+				}
+			}
+			if decodeErr != nil {
+				span.RecordError(decodeErr)
+			} else {
+				h.typed(limitDepth(tick, h.levels))
+			}
+		}
+		span.End()
+	}
+}
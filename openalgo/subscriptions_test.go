@@ -0,0 +1,44 @@
+package openalgo
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// TestDispatchMarketDataDecodeFailure checks that a tick that fails to
+// decode for typed handlers still reaches every raw and legacy handler
+func TestDispatchMarketDataDecodeFailure(t *testing.T) {
+	c := NewClient("test-key", "http://localhost")
+	key := subKey{exchange: "NSE", symbol: "SBIN", mode: ModeLTP}
+	var raw, legacy, typed int
+	c.ws.subscriptions = map[subKey]*subEntry{key: {
+		handlers: map[uint64]tickHandler{
+			1: {typed: func(Tick) { typed++ }},
+			2: {raw: func(interface{}) { raw++ }},
+			3: {typed: func(Tick) { typed++ }},
+			4: {raw: func(interface{}) { raw++ }},
+		},
+		legacy:    func(interface{}) { legacy++ },
+		hasLegacy: true,
+		lastTick:  time.Now(),
+	}}
+
+	msg := []byte(`{"type":"market_data","exchange":"NSE","symbol":"SBIN","mode":1,"data":{"ltp":"not a number"}}`)
+	var md MarketData
+	if err := json.Unmarshal(msg, &md); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	// Map iteration order varies, so dispatch a few times
+	const n = 20
+	for i := 0; i < n; i++ {
+		c.dispatchMarketData(md, msg)
+	}
+
+	if raw != 2*n || legacy != n {
+		t.Errorf("raw handlers got %d ticks and legacy %d, want %d and %d", raw, legacy, 2*n, n)
+	}
+	if typed != 0 {
+		t.Errorf("typed handlers got %d undecodable ticks, want 0", typed)
+	}
+}
@@ -0,0 +1,236 @@
+package openalgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DefaultDepthLevels is the number of depth levels subscribed to when none
+// is requested
+const DefaultDepthLevels = 5
+
+// Tick is a typed market data message received over the WebSocket. It is
+// one of LTPTick, QuoteTick or DepthTick.
+type Tick interface {
+	// Header returns the instrument, mode and time of the tick
+	Header() TickHeader
+}
+
+// TickHeader carries the fields common to every tick
+type TickHeader struct {
+	Exchange string
+	Symbol   string
+	Mode     Mode
+	// Timestamp is the exchange time sent by the server, or the time the
+	// tick was received when the server sends none
+	Timestamp time.Time
+}
+
+// Header implements Tick
+func (h TickHeader) Header() TickHeader {
+	return h
+}
+
+// Instrument returns the instrument the tick belongs to
+func (h TickHeader) Instrument() Instrument {
+	return Instrument{Exchange: h.Exchange, Symbol: h.Symbol}
+}
+
+// LTPTick is a last traded price update
+type LTPTick struct {
+	TickHeader
+	LTP float64
+}
+
+// QuoteTick is a quote update
+type QuoteTick struct {
+	TickHeader
+	LTP           float64
+	Open          float64
+	High          float64
+	Low           float64
+	Close         float64
+	Volume        int64
+	LastQuantity  int64
+	AveragePrice  float64
+	Change        float64
+	ChangePercent float64
+	OI            int64
+}
+
+// DepthTick is a market depth update
+type DepthTick struct {
+	TickHeader
+	LTP          float64
+	Open         float64
+	High         float64
+	Low          float64
+	Close        float64
+	Volume       int64
+	TotalBuyQty  int64
+	TotalSellQty int64
+	Bids         []DepthLevel
+	Asks         []DepthLevel
+}
+
+// tickData is the union of the fields sent in the data object of
+// market_data messages across modes and brokers
+type tickData struct {
+	LTP           FlexFloat       `json:"ltp"`
+	Open          FlexFloat       `json:"open"`
+	High          FlexFloat       `json:"high"`
+	Low           FlexFloat       `json:"low"`
+	Close         FlexFloat       `json:"close"`
+	Volume        FlexFloat       `json:"volume"`
+	LastQuantity  FlexFloat       `json:"last_quantity"`
+	LastTradeQty  FlexFloat       `json:"last_trade_quantity"`
+	AveragePrice  FlexFloat       `json:"average_price"`
+	AvgTradePrice FlexFloat       `json:"avg_trade_price"`
+	Change        FlexFloat       `json:"change"`
+	ChangePercent FlexFloat       `json:"change_percent"`
+	OI            FlexFloat       `json:"oi"`
+	TotalBuyQty   FlexFloat       `json:"total_buy_quantity"`
+	TotalSellQty  FlexFloat       `json:"total_sell_quantity"`
+	Timestamp     json.RawMessage `json:"timestamp"`
+	Depth         struct {
+		Buy  []DepthLevel `json:"buy"`
+		Sell []DepthLevel `json:"sell"`
+	} `json:"depth"`
+	Bids []DepthLevel `json:"bids"`
+	Asks []DepthLevel `json:"asks"`
+}
+
+// firstNonZero returns a unless it is zero, in which case it returns b
+func firstNonZero(a, b FlexFloat) FlexFloat {
+	if a != 0 {
+		return a
+	}
+	return b
+}
+
+// limitDepth returns t with the ladders of a DepthTick cut to levels, 0
+// meaning all levels
+func limitDepth(t Tick, levels int) Tick {
+	d, ok := t.(DepthTick)
+	if !ok || levels <= 0 {
+		return t
+	}
+	if len(d.Bids) > levels {
+		d.Bids = d.Bids[:levels:levels]
+	}
+	if len(d.Asks) > levels {
+		d.Asks = d.Asks[:levels:levels]
+	}
+	return d
+}
+
+// decodeTick decodes a market_data message into its typed tick
+func decodeTick(md MarketData, received time.Time) (Tick, error) {
+	var d tickData
+	if len(md.Data) > 0 {
+		if err := json.Unmarshal(md.Data, &d); err != nil {
+			return nil, fmt.Errorf("failed to decode %s tick for %s:%s: %w", Mode(md.Mode), md.Exchange, md.Symbol, err)
+		}
+	}
+
+	ts, err := parseTimestamp(d.Timestamp)
+	if err != nil || ts.IsZero() {
+		ts = received
+	}
+	header := TickHeader{
+		Exchange:  md.Exchange,
+		Symbol:    md.Symbol,
+		Mode:      Mode(md.Mode),
+		Timestamp: ts,
+	}
+
+	switch Mode(md.Mode) {
+	case ModeLTP:
+		return LTPTick{TickHeader: header, LTP: float64(d.LTP)}, nil
+	case ModeQuote:
+		return QuoteTick{
+			TickHeader:    header,
+			LTP:           float64(d.LTP),
+			Open:          float64(d.Open),
+			High:          float64(d.High),
+			Low:           float64(d.Low),
+			Close:         float64(d.Close),
+			Volume:        int64(d.Volume),
+			LastQuantity:  int64(firstNonZero(d.LastQuantity, d.LastTradeQty)),
+			AveragePrice:  float64(firstNonZero(d.AveragePrice, d.AvgTradePrice)),
+			Change:        float64(d.Change),
+			ChangePercent: float64(d.ChangePercent),
+			OI:            int64(d.OI),
+		}, nil
+	case ModeDepth:
+		bids, asks := d.Depth.Buy, d.Depth.Sell
+		if len(bids) == 0 && len(asks) == 0 {
+			bids, asks = d.Bids, d.Asks
+		}
+		return DepthTick{
+			TickHeader:   header,
+			LTP:          float64(d.LTP),
+			Open:         float64(d.Open),
+			High:         float64(d.High),
+			Low:          float64(d.Low),
+			Close:        float64(d.Close),
+			Volume:       int64(d.Volume),
+			TotalBuyQty:  int64(d.TotalBuyQty),
+			TotalSellQty: int64(d.TotalSellQty),
+			Bids:         bids,
+			Asks:         asks,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown market data mode %d for %s:%s", md.Mode, md.Exchange, md.Symbol)
+}
+
+// SubscribeLTPFunc subscribes handler to typed LTP ticks of instruments
+func (c *Client) SubscribeLTPFunc(instruments []Instrument, handler func(LTPTick)) (*Subscription, error) {
+	if handler == nil {
+		return nil, fmt.Errorf("%w: handler is required", ErrInvalidParameter)
+	}
+	return c.subscribeTicks(context.Background(), instruments, ModeLTP, 0, func(t Tick) {
+		if tick, ok := t.(LTPTick); ok {
+			handler(tick)
+		}
+	})
+}
+
+// SubscribeQuoteFunc subscribes handler to typed quote ticks of instruments
+func (c *Client) SubscribeQuoteFunc(instruments []Instrument, handler func(QuoteTick)) (*Subscription, error) {
+	if handler == nil {
+		return nil, fmt.Errorf("%w: handler is required", ErrInvalidParameter)
+	}
+	return c.subscribeTicks(context.Background(), instruments, ModeQuote, 0, func(t Tick) {
+		if tick, ok := t.(QuoteTick); ok {
+			handler(tick)
+		}
+	})
+}
+
+// SubscribeDepthFunc subscribes handler to typed depth ticks of instruments
+// with DefaultDepthLevels levels
+func (c *Client) SubscribeDepthFunc(instruments []Instrument, handler func(DepthTick)) (*Subscription, error) {
+	return c.SubscribeDepthLevels(instruments, DefaultDepthLevels, handler)
+}
+
+// SubscribeDepthLevels subscribes handler to typed depth ticks of instruments
+// with levels bid and ask levels, such as 5, 20 or 50 depending on the
+// broker. The ladders handed to handler hold at most levels entries. If the
+// server acknowledges fewer levels than requested, the subscription's Ack
+// reports ErrDepthUnavailable for the instrument.
+func (c *Client) SubscribeDepthLevels(instruments []Instrument, levels int, handler func(DepthTick)) (*Subscription, error) {
+	if handler == nil {
+		return nil, fmt.Errorf("%w: handler is required", ErrInvalidParameter)
+	}
+	if levels <= 0 {
+		return nil, fmt.Errorf("%w: depth levels must be positive, got %d", ErrInvalidParameter, levels)
+	}
+	return c.subscribeTicks(context.Background(), instruments, ModeDepth, levels, func(t Tick) {
+		if tick, ok := t.(DepthTick); ok {
+			handler(tick)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package openalgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// IST is Indian Standard Time, the timezone of the Indian exchanges. Times
+// sent by the server without an explicit zone are interpreted in IST.
+var IST = time.FixedZone("IST", 5*60*60+30*60)
+
+// timestampLayouts are the zone-less date-time formats seen in responses.
+// They are tried before epoch values so that compact dates such as 20240705
+// are not mistaken for epoch seconds.
+var timestampLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"02-01-2006 15:04:05",
+	"2006-01-02",
+	"20060102150405",
+	"20060102",
+}
+
+// parseTimestamp decodes a timestamp given as an epoch in seconds,
+// milliseconds, microseconds or nanoseconds, an RFC 3339 string or a
+// zone-less date-time in IST. The result is expressed in IST; null or empty
+// input yields the zero time.
+func parseTimestamp(data json.RawMessage) (time.Time, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || string(data) == "null" {
+		return time.Time{}, nil
+	}
+
+	s := string(data)
+	if data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return time.Time{}, err
+		}
+		if s == "" {
+			return time.Time{}, nil
+		}
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t.In(IST), nil
+	}
+	for _, layout := range timestampLayouts {
+		if t, err := time.ParseInLocation(layout, s, IST); err == nil {
+			return t, nil
+		}
+	}
+	if epoch, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return epochToTime(epoch), nil
+	}
+	if epoch, err := strconv.ParseFloat(s, 64); err == nil {
+		return floatEpochToTime(epoch)
+	}
+	return time.Time{}, fmt.Errorf("unrecognized timestamp %s", data)
+}
+
+// epochToTime converts an epoch to a time in IST, telling its unit apart by
+// magnitude: values below 1e11 are seconds (up to year 5138), below 1e14
+// milliseconds, below 1e17 microseconds and anything larger nanoseconds
+func epochToTime(epoch int64) time.Time {
+	abs := epoch
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case abs < 1e11:
+		return time.Unix(epoch, 0).In(IST)
+	case abs < 1e14:
+		return time.UnixMilli(epoch).In(IST)
+	case abs < 1e17:
+		return time.UnixMicro(epoch).In(IST)
+	default:
+		return time.Unix(0, epoch).In(IST)
+	}
+}
+
+// floatEpochToTime converts an epoch with a fractional part to a time in IST.
+// Fractions are kept for epoch seconds; larger units are rounded to whole
+// units first.
+func floatEpochToTime(epoch float64) (time.Time, error) {
+	if math.IsNaN(epoch) || epoch >= math.MaxInt64 || epoch <= math.MinInt64 {
+		return time.Time{}, fmt.Errorf("epoch %v out of range", epoch)
+	}
+	if math.Abs(epoch) >= 1e11 {
+		return epochToTime(int64(math.Round(epoch))), nil
+	}
+	sec, frac := math.Modf(epoch)
+	return time.Unix(int64(sec), int64(math.Round(frac*1e9))).In(IST), nil
+}
@@ -0,0 +1,95 @@
+package openalgo
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	// 2024-07-05 09:15:00 IST
+	want := time.Date(2024, 7, 5, 9, 15, 0, 0, IST)
+	tests := []struct {
+		name string
+		in   string
+		want time.Time
+	}{
+		{name: "epoch seconds", in: `1720151100`, want: want},
+		{name: "fractional epoch seconds", in: `1720151100.25`, want: want.Add(250 * time.Millisecond)},
+		{name: "epoch milliseconds", in: `1720151100000`, want: want},
+		{name: "epoch microseconds", in: `1720151100000000`, want: want},
+		{name: "epoch nanoseconds", in: `1720151100000000123`, want: want.Add(123)},
+		{name: "quoted epoch seconds", in: `"1720151100"`, want: want},
+		{name: "quoted epoch milliseconds", in: `"1720151100000"`, want: want},
+		{name: "negative epoch seconds", in: `-86400`, want: time.Date(1969, 12, 31, 5, 30, 0, 0, IST)},
+		{name: "RFC 3339 UTC", in: `"2024-07-05T03:45:00Z"`, want: want},
+		{name: "RFC 3339 with offset", in: `"2024-07-05T09:15:00+05:30"`, want: want},
+		{name: "date and time", in: `"2024-07-05 09:15:00"`, want: want},
+		{name: "date T time", in: `"2024-07-05T09:15:00"`, want: want},
+		{name: "date and minutes", in: `"2024-07-05 09:15"`, want: want},
+		{name: "day first", in: `"05-07-2024 09:15:00"`, want: want},
+		{name: "date", in: `"2024-07-05"`, want: time.Date(2024, 7, 5, 0, 0, 0, 0, IST)},
+		{name: "compact date", in: `"20240705"`, want: time.Date(2024, 7, 5, 0, 0, 0, 0, IST)},
+		{name: "unquoted compact date", in: `20240705`, want: time.Date(2024, 7, 5, 0, 0, 0, 0, IST)},
+		{name: "compact date-time", in: `"20240705091500"`, want: want},
+		{name: "null", in: `null`},
+		{name: "empty string", in: `""`},
+		{name: "empty", in: ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTimestamp(json.RawMessage(tt.in))
+			if err != nil {
+				t.Fatalf("parseTimestamp(%s): %v", tt.in, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseTimestamp(%s) = %s, want %s", tt.in, got, tt.want)
+			}
+			if !got.IsZero() && got.Location() != IST {
+				t.Errorf("parseTimestamp(%s) location = %s, want IST", tt.in, got.Location())
+			}
+		})
+	}
+}
+
+func TestParseTimestampInvalid(t *testing.T) {
+	for _, in := range []string{`"yesterday"`, `"2024-13-45"`, `true`, `"1e400"`, `"NaN"`} {
+		if got, err := parseTimestamp(json.RawMessage(in)); err == nil {
+			t.Errorf("parseTimestamp(%s) = %s, want an error", in, got)
+		}
+	}
+}
+
+func TestHistoryBarUnmarshalJSON(t *testing.T) {
+	var resp HistoryResponse
+	body := `{"status":"success","data":[
+		{"timestamp":1720151100,"open":"820.5","high":822,"low":"819.25","close":821,"volume":"1500","oi":0},
+		{"timestamp":"2024-07-05 09:16:00","open":821,"high":823,"low":820,"close":"822.5","volume":900}
+	]}`
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(resp.Data) != 2 {
+		t.Fatalf("got %d bars, want 2", len(resp.Data))
+	}
+
+	first, second := resp.Data[0], resp.Data[1]
+	if want := time.Date(2024, 7, 5, 9, 15, 0, 0, IST); !first.Timestamp.Equal(want) || first.Timestamp.Location() != IST {
+		t.Errorf("first bar timestamp = %s, want %s", first.Timestamp, want)
+	}
+	if want := time.Date(2024, 7, 5, 9, 16, 0, 0, IST); !second.Timestamp.Equal(want) {
+		t.Errorf("second bar timestamp = %s, want %s", second.Timestamp, want)
+	}
+	if first.Open != 820.5 || first.Low != 819.25 || first.Volume != 1500 {
+		t.Errorf("first bar = %+v, want open 820.5, low 819.25, volume 1500", first)
+	}
+	if second.Close != 822.5 || second.Volume != 900 {
+		t.Errorf("second bar = %+v, want close 822.5, volume 900", second)
+	}
+
+	var bar HistoryBar
+	if err := json.Unmarshal([]byte(`{"timestamp":"soon"}`), &bar); err == nil {
+		t.Error("Unmarshal of an unrecognized timestamp succeeded, want an error")
+	}
+}
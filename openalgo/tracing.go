@@ -0,0 +1,176 @@
+package openalgo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Tracer starts spans around the client's REST calls and WebSocket tick
+// handling. It is small enough to be adapted to OpenTelemetry or any other
+// tracing system; see the README for an OpenTelemetry adapter.
+// Implementations must be safe for concurrent use.
+type Tracer interface {
+	// Start starts a span named name as a child of the span in ctx, if any,
+	// and returns a context carrying the new span
+	Start(ctx context.Context, name string, cfg SpanConfig) (context.Context, Span)
+	// Inject writes the trace context of the span in ctx to header, e.g. as
+	// a W3C traceparent header
+	Inject(ctx context.Context, header http.Header)
+}
+
+// SpanConfig describes a span to start
+type SpanConfig struct {
+	Attributes []Attribute
+	// Links are contexts carrying spans the new span is linked to rather
+	// than a child of. Tick handling spans link to their subscribing span.
+	Links []context.Context
+}
+
+// Span is a span started by a Tracer
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	// RecordError records err on the span and marks the span as failed
+	RecordError(err error)
+	End()
+}
+
+// Attribute is a key-value pair attached to a span. Value is a string, int,
+// int64, float64 or bool.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Span attribute keys set by the client
+const (
+	attrEndpoint    = "openalgo.endpoint"
+	attrMethod      = "http.request.method"
+	attrStrategy    = "openalgo.strategy"
+	attrExchange    = "openalgo.exchange"
+	attrSymbol      = "openalgo.symbol"
+	attrAttempts    = "openalgo.attempts"
+	attrHTTPStatus  = "http.response.status_code"
+	attrStatus      = "openalgo.status"
+	attrMode        = "openalgo.mode"
+	attrInstruments = "openalgo.instruments"
+	attrAckStatus   = "openalgo.ack_status"
+)
+
+// nopSpan is returned when no Tracer is configured
+type nopSpan struct{}
+
+func (nopSpan) SetAttributes(...Attribute) {}
+func (nopSpan) RecordError(error)          {}
+func (nopSpan) End()                       {}
+
+// startSpan starts a span with the client's tracer, or returns ctx and a
+// no-op span when tracing is disabled
+func (c *Client) startSpan(ctx context.Context, name string, cfg SpanConfig) (context.Context, Span) {
+	if c.tracer == nil {
+		return ctx, nopSpan{}
+	}
+	return c.tracer.Start(ctx, name, cfg)
+}
+
+// requestAttributes returns the span attributes of a REST call, taking the
+// strategy, exchange and symbol from its payload when present
+func requestAttributes(method, endpoint string, payload map[string]interface{}) []Attribute {
+	attrs := []Attribute{{Key: attrEndpoint, Value: endpoint}, {Key: attrMethod, Value: method}}
+	for _, field := range []struct{ name, key string }{
+		{"strategy", attrStrategy},
+		{"exchange", attrExchange},
+		{"symbol", attrSymbol},
+	} {
+		if v, ok := payload[field.name].(string); ok && v != "" {
+			attrs = append(attrs, Attribute{Key: field.key, Value: v})
+		}
+	}
+	return attrs
+}
+
+// startSubscribeSpan starts the span of a subscription to instruments in
+// mode. The returned context is kept with the subscription's handler so
+// that tick handling spans can link to it.
+func (c *Client) startSubscribeSpan(ctx context.Context, instruments []Instrument, mode Mode) (context.Context, Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return c.startSpan(ctx, "openalgo.subscribe", SpanConfig{Attributes: []Attribute{
+		{Key: attrMode, Value: mode.String()},
+		{Key: attrInstruments, Value: len(instruments)},
+	}})
+}
+
+// subscribeSpanLimit bounds how long a subscribe span waits for the
+// server's acknowledgement, which never comes when the ack timeout is
+// disabled and the server stays silent
+const subscribeSpanLimit = time.Minute
+
+// endSubscribeSpan ends span once every instrument of ack is resolved,
+// recording the instruments that were not accepted, or after
+// subscribeSpanLimit at the latest
+func (c *Client) endSubscribeSpan(span Span, ack *SubscriptionAck) {
+	if c.tracer == nil {
+		return
+	}
+	var once sync.Once
+	end := func(err error) {
+		once.Do(func() {
+			status := AckAccepted
+			for _, r := range ack.Results() {
+				if r.Status != AckAccepted {
+					status = r.Status
+					break
+				}
+			}
+			span.SetAttributes(Attribute{Key: attrAckStatus, Value: status.String()})
+			if err != nil {
+				span.RecordError(err)
+			}
+			span.End()
+		})
+	}
+	timer := time.AfterFunc(subscribeSpanLimit, func() {
+		end(fmt.Errorf("%w within %s", ErrNoAcknowledgement, subscribeSpanLimit))
+	})
+	ack.whenDone(func() {
+		timer.Stop()
+		end(ack.failures())
+	})
+}
+
+// startTickSpan starts the span of handing a market data message to h,
+// linked to the span that subscribed h. It returns a no-op span when h was
+// subscribed without tracing.
+func (c *Client) startTickSpan(h tickHandler, md MarketData) Span {
+	if h.trace == nil || c.tracer == nil {
+		return nopSpan{}
+	}
+	_, span := c.tracer.Start(context.Background(), "openalgo.tick", SpanConfig{
+		Attributes: []Attribute{
+			{Key: attrExchange, Value: md.Exchange},
+			{Key: attrSymbol, Value: md.Symbol},
+			{Key: attrMode, Value: Mode(md.Mode).String()},
+		},
+		Links: []context.Context{h.trace},
+	})
+	return span
+}
+
+// endRequestSpan records the outcome of a REST call on span and ends it
+func endRequestSpan(span Span, attempts int, resp response, err error) {
+	span.SetAttributes(Attribute{Key: attrAttempts, Value: attempts})
+	if resp.status != 0 {
+		span.SetAttributes(Attribute{Key: attrHTTPStatus, Value: resp.status})
+	}
+	if status, ok := resp.result["status"].(string); ok {
+		span.SetAttributes(Attribute{Key: attrStatus, Value: status})
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
@@ -0,0 +1,189 @@
+package openalgo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type spanContextKey struct{}
+
+// recordedSpan is a span started by a recordingTracer
+type recordedSpan struct {
+	tracer *recordingTracer
+	name   string
+	parent *recordedSpan
+	links  []*recordedSpan
+	attrs  map[string]interface{}
+	err    error
+	ended  bool
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...Attribute) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *recordedSpan) RecordError(err error) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.err = err
+}
+
+func (s *recordedSpan) End() {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.ended = true
+}
+
+// recordingTracer is a Tracer keeping every span it starts
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, cfg SpanConfig) (context.Context, Span) {
+	span := &recordedSpan{tracer: t, name: name, attrs: make(map[string]interface{})}
+	span.parent, _ = ctx.Value(spanContextKey{}).(*recordedSpan)
+	for _, link := range cfg.Links {
+		if l, ok := link.Value(spanContextKey{}).(*recordedSpan); ok {
+			span.links = append(span.links, l)
+		}
+	}
+	for _, a := range cfg.Attributes {
+		span.attrs[a.Key] = a.Value
+	}
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+	return context.WithValue(ctx, spanContextKey{}, span), span
+}
+
+func (t *recordingTracer) Inject(ctx context.Context, header http.Header) {
+	if span, ok := ctx.Value(spanContextKey{}).(*recordedSpan); ok {
+		header.Set("X-Test-Span", span.name)
+	}
+}
+
+// find returns the first span named name, or nil
+func (t *recordingTracer) find(name string) *recordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, span := range t.spans {
+		if span.name == name {
+			return span
+		}
+	}
+	return nil
+}
+
+// ended reports whether span has ended
+func (t *recordingTracer) ended(span *recordedSpan) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return span.ended
+}
+
+func TestTracerRequestSpan(t *testing.T) {
+	var injected string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		injected = r.Header.Get("X-Test-Span")
+		fmt.Fprint(w, `{"status":"success","orderid":"1"}`)
+	}))
+	defer srv.Close()
+	tracer := &recordingTracer{}
+	c, err := NewClientWithOptions("test-key", srv.URL, WithTracer(tracer))
+	if err != nil {
+		t.Fatalf("NewClientWithOptions: %v", err)
+	}
+
+	ctx, parent := tracer.Start(context.Background(), "strategy", SpanConfig{})
+	if _, err := c.PlaceOrderCtx(ctx, "S1", "SBIN", "BUY", "NSE", "MARKET", "MIS", 1); err != nil {
+		t.Fatalf("PlaceOrderCtx: %v", err)
+	}
+	parent.End()
+
+	span := tracer.find("openalgo.placeorder")
+	if span == nil {
+		t.Fatal("no placeorder span")
+	}
+	if span.parent != parent || !span.ended || injected != span.name {
+		t.Errorf("span parent = %v, ended = %v, injected %q; want child of strategy, ended and injected", span.parent, span.ended, injected)
+	}
+	for key, want := range map[string]interface{}{
+		attrStrategy: "S1", attrSymbol: "SBIN", attrExchange: "NSE",
+		attrAttempts: 1, attrHTTPStatus: 200, attrStatus: "success",
+	} {
+		if span.attrs[key] != want {
+			t.Errorf("attribute %s = %v, want %v", key, span.attrs[key], want)
+		}
+	}
+}
+
+func TestTracerTickSpansLinkToSubscription(t *testing.T) {
+	server := newFakeWSServer(t)
+	tracer := &recordingTracer{}
+	c := newTestWSClient(t, server, WithTracer(tracer))
+
+	ticks := make(chan struct{}, 1)
+	if _, err := c.SubscribeLTPFunc([]Instrument{{Exchange: "NSE", Symbol: "SBIN"}}, func(LTPTick) {
+		select {
+		case ticks <- struct{}{}:
+		default:
+		}
+	}); err != nil {
+		t.Fatalf("SubscribeLTPFunc: %v", err)
+	}
+	select {
+	case <-ticks:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no tick received")
+	}
+
+	sub := tracer.find("openalgo.subscribe")
+	waitFor(t, 5*time.Second, "subscribe span to end", func() bool { return tracer.ended(sub) })
+	waitFor(t, 5*time.Second, "tick span", func() bool { return tracer.find("openalgo.tick") != nil })
+	tick := tracer.find("openalgo.tick")
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+	if len(tick.links) != 1 || tick.links[0] != sub {
+		t.Errorf("tick span links = %v, want the subscribe span", tick.links)
+	}
+	if sub.attrs[attrAckStatus] != "accepted" {
+		t.Errorf("subscribe span ack status = %v, want accepted", sub.attrs[attrAckStatus])
+	}
+}
+
+func TestTracerSubscribeSpanEndsOnDisconnect(t *testing.T) {
+	server := newFakeWSServer(t)
+	server.silent = true
+	tracer := &recordingTracer{}
+	// Without an ack timeout only Disconnect resolves the subscription
+	c := newTestWSClient(t, server, WithTracer(tracer), WithAckTimeout(0))
+
+	if _, err := c.Subscribe([]Instrument{{Exchange: "NSE", Symbol: "SBIN"}}, ModeLTP, func(interface{}) {}); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	sub := tracer.find("openalgo.subscribe")
+	time.Sleep(50 * time.Millisecond)
+	if tracer.ended(sub) {
+		t.Fatal("subscribe span ended before the subscription was resolved")
+	}
+
+	c.Disconnect()
+	if !tracer.ended(sub) {
+		t.Fatal("subscribe span still open after Disconnect")
+	}
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+	if sub.err == nil || sub.attrs[attrAckStatus] != "unknown" {
+		t.Errorf("subscribe span error = %v, ack status = %v; want an error and unknown", sub.err, sub.attrs[attrAckStatus])
+	}
+}
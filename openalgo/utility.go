@@ -1,9 +1,16 @@
 package openalgo
 
+import "context"
+
 // Ping checks API connectivity
 func (c *Client) Ping() (map[string]interface{}, error) {
+	return c.PingCtx(context.Background())
+}
+
+// PingCtx is like Ping but honors ctx for cancellation and deadlines
+func (c *Client) PingCtx(ctx context.Context) (map[string]interface{}, error) {
 	payload := map[string]interface{}{
 		"apikey": c.apiKey,
 	}
-	return c.makeRequest("POST", "ping", payload)
-}
\ No newline at end of file
+	return c.makeRequest(ctx, "POST", "ping", payload)
+}
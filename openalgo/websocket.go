@@ -1,9 +1,10 @@
 package openalgo
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"sync"
 	"time"
 
@@ -32,361 +33,510 @@ type AuthMessage struct {
 	APIKey string `json:"api_key"`
 }
 
-// MarketData represents the market data received from WebSocket
+// MarketData represents the market data received from WebSocket. Data is
+// decoded into a typed Tick for the typed subscription APIs.
 type MarketData struct {
-	Type      string      `json:"type"`
-	Symbol    string      `json:"symbol"`
-	Exchange  string      `json:"exchange"`
-	Mode      int         `json:"mode"`
-	Data      interface{} `json:"data"`
+	Type     string          `json:"type"`
+	Symbol   string          `json:"symbol"`
+	Exchange string          `json:"exchange"`
+	Mode     int             `json:"mode"`
+	Data     json.RawMessage `json:"data"`
 }
 
-// Connect establishes a WebSocket connection and authenticates
-func (c *Client) Connect() error {
-	if c.wsURL == "" {
-		return fmt.Errorf("WebSocket URL not provided")
-	}
+// ReconnectPolicy controls how a dropped WebSocket connection is
+// re-established
+type ReconnectPolicy struct {
+	// Disabled turns automatic reconnection off
+	Disabled bool
+	// MaxAttempts is the number of reconnection attempts after a drop,
+	// 0 means retry until Disconnect is called
+	MaxAttempts int
+	// InitialBackoff is the delay before the first attempt
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts
+	MaxBackoff time.Duration
+	// Multiplier is the factor applied to the delay after every attempt
+	Multiplier float64
+	// Jitter is the fraction, between 0 and 1, by which each delay is
+	// randomly shortened
+	Jitter float64
+}
 
-	conn, _, err := websocket.DefaultDialer.Dial(c.wsURL, nil)
-	if err != nil {
-		return fmt.Errorf("failed to connect to WebSocket: %w", err)
+// DefaultReconnectPolicy returns the policy used when none is configured:
+// unlimited attempts with exponential backoff from 500ms up to 30s
+func DefaultReconnectPolicy() ReconnectPolicy {
+	return ReconnectPolicy{
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
 	}
-	c.wsConn = conn
+}
 
-	// Authenticate using the same format as Python SDK
-	authMsg := AuthMessage{
-		Action: "authenticate",
-		APIKey: c.apiKey,
+func (p ReconnectPolicy) validate() error {
+	if p.MaxAttempts < 0 {
+		return fmt.Errorf("reconnect max attempts must not be negative, got %d", p.MaxAttempts)
 	}
-
-	if err := c.wsConn.WriteJSON(authMsg); err != nil {
-		return fmt.Errorf("failed to authenticate: %w", err)
+	if p.InitialBackoff < 0 || p.MaxBackoff < 0 {
+		return fmt.Errorf("reconnect backoff must not be negative")
 	}
-
-	// Start message reader
-	go c.readMessages()
-
-	log.Printf("Connected to %s", c.wsURL)
-	return nil
-}
-
-// Disconnect closes the WebSocket connection
-func (c *Client) Disconnect() error {
-	if c.wsConn != nil {
-		log.Printf("Disconnected from %s", c.wsURL)
-		return c.wsConn.Close()
+	if p.Multiplier != 0 && p.Multiplier < 1 {
+		return fmt.Errorf("reconnect multiplier must be at least 1, got %g", p.Multiplier)
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return fmt.Errorf("reconnect jitter must be between 0 and 1, got %g", p.Jitter)
 	}
 	return nil
 }
 
-// readMessages reads and processes incoming WebSocket messages
-func (c *Client) readMessages() {
-	for {
-		_, message, err := c.wsConn.ReadMessage()
-		if err != nil {
-			log.Printf("WebSocket read error: %v", err)
-			return
-		}
+// wsState holds the WebSocket connection together with the subscriptions
+// that are replayed whenever the connection is re-established
+type wsState struct {
+	// connectMu serializes Connect and Disconnect
+	connectMu sync.Mutex
+
+	// mu guards every field below
+	mu   sync.Mutex
+	conn *wsConn
+	// connectedAt is when conn was established
+	connectedAt time.Time
+	// closed is closed by Disconnect to stop a pending reconnection and the
+	// stale watchdog
+	closed chan struct{}
+	// subMu serializes changes to the subscriptions so that subscribe and
+	// unsubscribe messages reach the server in registry order
+	subMu sync.Mutex
+	// subscriptions maps exchange, symbol and mode to the active
+	// server-side subscription and its handlers
+	subscriptions map[subKey]*subEntry
+	// legacyHandlers holds the last handler passed to SubscribeLTP,
+	// SubscribeQuote and SubscribeDepth per mode
+	legacyHandlers map[Mode]func(interface{})
+	nextID         uint64
+	// queue holds subscription messages waiting to be sent, acks the sent
+	// ones awaiting the server's status reply
+	queue subQueue
+	acks  map[ackKey][]pendingAck
+	// streams holds the open TickStreams, closed by Disconnect
+	streams map[*TickStream]bool
+
+	onConnect    func()
+	onDisconnect func(error)
+	onReconnect  func(attempt int, err error)
+	onStale      func(instrument Instrument, lastTickAge time.Duration)
+}
 
-		var data map[string]interface{}
-		if err := json.Unmarshal(message, &data); err != nil {
-			log.Printf("Failed to unmarshal message: %v", err)
-			continue
-		}
+// OnConnect registers fn to be called whenever the WebSocket connection is
+// established, including after a successful reconnection
+func (c *Client) OnConnect(fn func()) {
+	c.ws.mu.Lock()
+	defer c.ws.mu.Unlock()
+	c.ws.onConnect = fn
+}
 
-		// Check for message type
-		if msgType, ok := data["type"].(string); ok && msgType == "market_data" {
-			// Extract mode to determine data type
-			mode := 0
-			if m, ok := data["mode"].(float64); ok {
-				mode = int(m)
-			}
+// OnDisconnect registers fn to be called when the WebSocket connection is
+// lost. err is nil when the connection was closed by Disconnect.
+func (c *Client) OnDisconnect(fn func(err error)) {
+	c.ws.mu.Lock()
+	defer c.ws.mu.Unlock()
+	c.ws.onDisconnect = fn
+}
 
-			// Route to appropriate callback based on mode
-			switch mode {
-			case 1: // LTP
-				if callback, exists := c.callbacks["ltp"]; exists {
-					callback(data)
-				}
-			case 2: // Quote
-				if callback, exists := c.callbacks["quote"]; exists {
-					callback(data)
-				}
-			case 3: // Depth
-				if callback, exists := c.callbacks["depth"]; exists {
-					callback(data)
-				}
-			}
-		} else if status, ok := data["status"].(string); ok {
-			// Handle status messages
-			if message, ok := data["message"].(string); ok {
-				log.Printf("WebSocket status: %s - %s", status, message)
-			}
-		}
-	}
+// OnReconnect registers fn to be called after the connection has been
+// re-established and the server has answered the replay of every active
+// subscription. err is nil when every replay was accepted; otherwise it
+// joins the failure of every instrument that was rejected or not
+// acknowledged. fn is not called if Disconnect is called first.
+func (c *Client) OnReconnect(fn func(attempt int, err error)) {
+	c.ws.mu.Lock()
+	defer c.ws.mu.Unlock()
+	c.ws.onReconnect = fn
 }
 
-// SubscribeLTP subscribes to Last Traded Price updates
-func (c *Client) SubscribeLTP(instruments []Instrument, onDataReceived func(interface{})) error {
-	if c.wsConn == nil {
-		return fmt.Errorf("not connected to WebSocket server")
+// Connect establishes a WebSocket connection and authenticates, waiting for
+// the server to accept the API key. A rejected key is reported as an
+// *APIError matching ErrInvalidAPIKey; other authentication failures, such
+// as a connection limit, are *APIErrors matching no sentinel. Subscriptions left over from a lost
+// connection, for example after reconnecting gave up, are replayed.
+func (c *Client) Connect() error {
+	if c.wsURL == "" {
+		return fmt.Errorf("WebSocket URL not provided")
 	}
 
-	// Set callback
-	if onDataReceived != nil {
-		c.callbacks["ltp"] = onDataReceived
+	c.ws.connectMu.Lock()
+	defer c.ws.connectMu.Unlock()
+
+	c.ws.mu.Lock()
+	if c.ws.conn != nil {
+		c.ws.mu.Unlock()
+		return nil
 	}
+	// Stop a reconnection in progress, this call takes over
+	if c.ws.closed != nil {
+		close(c.ws.closed)
+		c.ws.closed = nil
+	}
+	c.ws.mu.Unlock()
 
-	// Subscribe to each instrument individually (matching Python SDK)
-	for _, instrument := range instruments {
-		symbol := instrument.Symbol
-		exchange := instrument.Exchange
+	conn, err := c.dialWS()
+	if err != nil {
+		return err
+	}
 
-		// Use exchange_token as symbol if symbol is not provided
-		if symbol == "" && instrument.ExchangeToken != "" {
-			symbol = instrument.ExchangeToken
-		}
+	c.ws.mu.Lock()
+	c.ws.conn = conn
+	c.ws.connectedAt = time.Now()
+	closed := make(chan struct{})
+	c.ws.closed = closed
+	onConnect := c.ws.onConnect
+	c.ws.mu.Unlock()
+
+	subs := c.activeSubscriptions()
+
+	// Start message reader and subscription sender
+	go c.readMessages(conn)
+	go c.sendSubscriptions(closed)
+	if c.heartbeat.StaleAfter > 0 {
+		go c.staleWatchdog(closed)
+	}
+	if len(subs) > 0 {
+		go c.awaitReplay(c.queueReplay(subs), closed, 0, nil)
+	}
 
-		if exchange == "" || symbol == "" {
-			log.Printf("Invalid instrument: %+v", instrument)
-			continue
-		}
+	c.logger.Info("websocket connected", "url", c.wsURL, "subscriptions", len(subs))
+	if onConnect != nil {
+		onConnect()
+	}
+	return nil
+}
 
-		msg := SubscriptionMessage{
-			Action:   "subscribe",
-			Symbol:   symbol,
-			Exchange: exchange,
-			Mode:     1, // 1 for LTP
-			Depth:    5, // Default depth level
-		}
+// dialWS opens a new WebSocket connection, sends the authentication message
+// and starts the connection's writer goroutine
+func (c *Client) dialWS() (*wsConn, error) {
+	conn, _, err := c.wsDialer.Dial(c.wsURL, c.wsHeader.Clone())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to WebSocket: %w", err)
+	}
 
-		log.Printf("Subscribing to %s:%s LTP", exchange, symbol)
-		if err := c.wsConn.WriteJSON(msg); err != nil {
-			return fmt.Errorf("error subscribing to %s:%s: %w", exchange, symbol, err)
-		}
+	// Authenticate using the same format as Python SDK
+	authMsg := AuthMessage{
+		Action: "authenticate",
+		APIKey: c.apiKey,
+	}
 
-		// Small delay to ensure message is processed separately
-		time.Sleep(100 * time.Millisecond)
+	if err := conn.WriteJSON(authMsg); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to authenticate: %w", err)
 	}
+	if err := c.awaitAuth(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return newWSConn(conn, c.heartbeat), nil
+}
 
-	return nil
+// authReply is the server's answer to the authentication message
+type authReply struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Code    string `json:"code"`
 }
 
-// UnsubscribeLTP unsubscribes from LTP updates
-func (c *Client) UnsubscribeLTP(instruments []Instrument) error {
-	if c.wsConn == nil {
-		return fmt.Errorf("not connected to WebSocket server")
+// awaitAuth reads from conn until the server answers the authentication
+// message or the auth timeout passes. It runs before the reader goroutine
+// is started.
+func (c *Client) awaitAuth(conn *websocket.Conn) error {
+	if c.authTimeout <= 0 {
+		return nil
 	}
+	conn.SetReadDeadline(time.Now().Add(c.authTimeout))
+	defer conn.SetReadDeadline(time.Time{})
 
-	// Unsubscribe from each instrument individually
-	for _, instrument := range instruments {
-		symbol := instrument.Symbol
-		exchange := instrument.Exchange
-
-		if symbol == "" && instrument.ExchangeToken != "" {
-			symbol = instrument.ExchangeToken
+	for {
+		_, raw, err := conn.ReadMessage()
+		if err != nil {
+			return fmt.Errorf("failed to authenticate: no reply from server: %w", err)
 		}
 
-		if exchange == "" || symbol == "" {
-			log.Printf("Invalid instrument: %+v", instrument)
+		var reply authReply
+		if err := json.Unmarshal(raw, &reply); err != nil || (reply.Status == "" && reply.Type != "error") {
 			continue
 		}
-
-		msg := SubscriptionMessage{
-			Action:   "unsubscribe",
-			Symbol:   symbol,
-			Exchange: exchange,
-			Mode:     1, // 1 for LTP
+		if reply.Type != "" && reply.Type != "auth" && reply.Type != "error" {
+			continue
 		}
-
-		log.Printf("Unsubscribing from %s:%s LTP", exchange, symbol)
-		if err := c.wsConn.WriteJSON(msg); err != nil {
-			return fmt.Errorf("error unsubscribing from %s:%s: %w", exchange, symbol, err)
+		if reply.Status == "success" {
+			return nil
 		}
-
-		time.Sleep(100 * time.Millisecond)
+		message := reply.Message
+		if message == "" {
+			message = reply.Code
+		}
+		return newAPIError("authenticate", 0, reply.Status, message, raw)
 	}
-
-	// Remove callback
-	delete(c.callbacks, "ltp")
-	return nil
 }
 
-// SubscribeQuote subscribes to Quote updates
-func (c *Client) SubscribeQuote(instruments []Instrument, onDataReceived func(interface{})) error {
-	if c.wsConn == nil {
-		return fmt.Errorf("not connected to WebSocket server")
+// Disconnect closes the WebSocket connection and stops any reconnection in
+// progress. Subscriptions are forgotten and every TickStream is closed.
+func (c *Client) Disconnect() error {
+	c.ws.connectMu.Lock()
+	defer c.ws.connectMu.Unlock()
+
+	c.ws.mu.Lock()
+	conn := c.ws.conn
+	c.ws.conn = nil
+	if c.ws.closed != nil {
+		close(c.ws.closed)
+		c.ws.closed = nil
 	}
-
-	// Set callback
-	if onDataReceived != nil {
-		c.callbacks["quote"] = onDataReceived
+	c.ws.subscriptions = nil
+	c.ws.legacyHandlers = nil
+	streams := c.ws.streams
+	c.ws.streams = nil
+	onDisconnect := c.ws.onDisconnect
+	c.ws.mu.Unlock()
+
+	for s := range streams {
+		s.Close()
 	}
 
-	// Subscribe to each instrument individually
-	for _, instrument := range instruments {
-		symbol := instrument.Symbol
-		exchange := instrument.Exchange
-
-		if symbol == "" && instrument.ExchangeToken != "" {
-			symbol = instrument.ExchangeToken
-		}
-
-		if exchange == "" || symbol == "" {
-			log.Printf("Invalid instrument: %+v", instrument)
-			continue
-		}
-
-		msg := SubscriptionMessage{
-			Action:   "subscribe",
-			Symbol:   symbol,
-			Exchange: exchange,
-			Mode:     2, // 2 for Quote
-			Depth:    5,
-		}
-
-		log.Printf("Subscribing to %s:%s Quote", exchange, symbol)
-		if err := c.wsConn.WriteJSON(msg); err != nil {
-			return fmt.Errorf("error subscribing to %s:%s: %w", exchange, symbol, err)
-		}
+	for _, item := range c.ws.queue.drain() {
+		c.resolveSubscription(item, AckUnknown, ErrNotConnected)
+	}
+	c.failPendingAcks(nil, ErrNotConnected)
 
-		time.Sleep(100 * time.Millisecond)
+	if conn == nil {
+		return nil
+	}
+	c.logger.Info("websocket disconnected", "url", c.wsURL)
+	err := conn.close()
+	if onDisconnect != nil {
+		onDisconnect(nil)
 	}
+	return err
+}
 
-	return nil
+// currentConn returns the live connection, or nil when not connected
+func (c *Client) currentConn() *wsConn {
+	c.ws.mu.Lock()
+	defer c.ws.mu.Unlock()
+	return c.ws.conn
 }
 
-// UnsubscribeQuote unsubscribes from Quote updates
-func (c *Client) UnsubscribeQuote(instruments []Instrument) error {
-	if c.wsConn == nil {
-		return fmt.Errorf("not connected to WebSocket server")
+// handleConnectionLost is called by the reader of conn when it fails. It
+// notifies the disconnect handler and starts reconnecting unless conn has
+// already been replaced or closed by Disconnect.
+func (c *Client) handleConnectionLost(conn *wsConn, err error) {
+	c.ws.mu.Lock()
+	if c.ws.conn != conn {
+		c.ws.mu.Unlock()
+		return
+	}
+	c.ws.conn = nil
+	closed := c.ws.closed
+	onDisconnect := c.ws.onDisconnect
+	c.ws.mu.Unlock()
+
+	conn.close()
+	c.failPendingAcks(conn, err)
+	c.logger.Warn("websocket connection lost", "url", c.wsURL, "error", err)
+	if onDisconnect != nil {
+		onDisconnect(err)
 	}
 
-	// Unsubscribe from each instrument individually
-	for _, instrument := range instruments {
-		symbol := instrument.Symbol
-		exchange := instrument.Exchange
+	if !c.reconnect.Disabled {
+		go c.reconnectLoop(closed)
+	}
+}
 
-		if symbol == "" && instrument.ExchangeToken != "" {
-			symbol = instrument.ExchangeToken
+// reconnectLoop re-establishes the connection with backoff, replaying every
+// active subscription, until it succeeds, the attempts are exhausted or
+// Disconnect closes closed
+func (c *Client) reconnectLoop(closed chan struct{}) {
+	p := c.reconnect
+	for attempt := 1; p.MaxAttempts == 0 || attempt <= p.MaxAttempts; attempt++ {
+		timer := time.NewTimer(backoffDelay(attempt, p.InitialBackoff, p.MaxBackoff, p.Multiplier, p.Jitter))
+		select {
+		case <-closed:
+			timer.Stop()
+			return
+		case <-timer.C:
 		}
 
-		if exchange == "" || symbol == "" {
-			log.Printf("Invalid instrument: %+v", instrument)
+		conn, err := c.dialWS()
+		c.metrics.ObserveReconnect(attempt, err)
+		if errors.Is(err, ErrInvalidAPIKey) {
+			c.logger.Error("websocket reconnect abandoned", "url", c.wsURL, "attempt", attempt, "error", err)
+			return
+		}
+		if err != nil {
+			c.logger.Warn("websocket reconnect failed", "url", c.wsURL, "attempt", attempt, "error", err)
 			continue
 		}
 
-		msg := SubscriptionMessage{
-			Action:   "unsubscribe",
-			Symbol:   symbol,
-			Exchange: exchange,
-			Mode:     2, // 2 for Quote
+		c.ws.mu.Lock()
+		select {
+		case <-closed:
+			c.ws.mu.Unlock()
+			conn.close()
+			return
+		default:
 		}
+		c.ws.conn = conn
+		c.ws.connectedAt = time.Now()
+		onConnect, onReconnect := c.ws.onConnect, c.ws.onReconnect
+		c.ws.mu.Unlock()
 
-		log.Printf("Unsubscribing from %s:%s Quote", exchange, symbol)
-		if err := c.wsConn.WriteJSON(msg); err != nil {
-			return fmt.Errorf("error unsubscribing from %s:%s: %w", exchange, symbol, err)
-		}
+		subs := c.activeSubscriptions()
 
-		time.Sleep(100 * time.Millisecond)
-	}
+		go c.readMessages(conn)
 
-	// Remove callback
-	delete(c.callbacks, "quote")
-	return nil
-}
+		// Replay active subscriptions through the subscription queue
+		replay := c.queueReplay(subs)
 
-// SubscribeDepth subscribes to Market Depth updates
-func (c *Client) SubscribeDepth(instruments []Instrument, onDataReceived func(interface{})) error {
-	if c.wsConn == nil {
-		return fmt.Errorf("not connected to WebSocket server")
+		c.logger.Info("websocket reconnected", "url", c.wsURL, "attempt", attempt, "subscriptions", len(subs))
+		if onConnect != nil {
+			onConnect()
+		}
+		go c.awaitReplay(replay, closed, attempt, onReconnect)
+		return
 	}
+	c.logger.Error("websocket reconnect abandoned", "url", c.wsURL, "attempt", p.MaxAttempts)
+}
 
-	// Set callback
-	if onDataReceived != nil {
-		c.callbacks["depth"] = onDataReceived
+// queueReplay queues a subscribe message for every message of subs and
+// returns the ack resolved by the server's replies
+func (c *Client) queueReplay(subs []SubscriptionMessage) *SubscriptionAck {
+	keys := make([]subKey, len(subs))
+	pending := make(map[subKey]bool, len(subs))
+	for i, msg := range subs {
+		keys[i] = subKey{exchange: msg.Exchange, symbol: msg.Symbol, mode: Mode(msg.Mode)}
+		pending[keys[i]] = true
+	}
+	ack := newSubscriptionAck(keys, pending)
+	items := make([]queuedMessage, len(subs))
+	for i, msg := range subs {
+		items[i] = queuedMessage{msg: msg, ack: ack, index: i}
 	}
+	c.ws.queue.push(items...)
+	return ack
+}
 
-	// Subscribe to each instrument individually
-	for _, instrument := range instruments {
-		symbol := instrument.Symbol
-		exchange := instrument.Exchange
+// awaitReplay logs the failures of replay once it is resolved and calls
+// onReconnect, if set, unless Disconnect closes closed first. attempt is 0
+// for a replay started by Connect.
+func (c *Client) awaitReplay(replay *SubscriptionAck, closed chan struct{}, attempt int, onReconnect func(int, error)) {
+	select {
+	case <-replay.Done():
+	case <-closed:
+		return
+	}
+	err := replay.Wait(context.Background())
+	if err != nil {
+		c.logger.Warn("subscriptions not restored after reconnect", "attempt", attempt, "error", err)
+	}
+	if onReconnect != nil {
+		onReconnect(attempt, err)
+	}
+}
 
-		if symbol == "" && instrument.ExchangeToken != "" {
-			symbol = instrument.ExchangeToken
+// readMessages reads and processes incoming WebSocket messages from conn
+// until it fails or stays silent past the heartbeat's pong wait. It is the
+// only goroutine reading from conn.
+func (c *Client) readMessages(conn *wsConn) {
+	for {
+		_, raw, err := conn.conn.ReadMessage()
+		if err != nil {
+			c.handleConnectionLost(conn, err)
+			return
 		}
-
-		if exchange == "" || symbol == "" {
-			log.Printf("Invalid instrument: %+v", instrument)
-			continue
+		conn.extendReadDeadline()
+
+		var md MarketData
+		if err := json.Unmarshal(raw, &md); err == nil {
+			switch md.Type {
+			case "market_data":
+				c.dispatchMarketData(md, raw)
+				continue
+			case "subscribe", "unsubscribe":
+				var reply subscriptionReply
+				if err := json.Unmarshal(raw, &reply); err == nil {
+					c.handleSubscriptionReply(reply, raw)
+					continue
+				}
+			}
 		}
 
-		msg := SubscriptionMessage{
-			Action:   "subscribe",
-			Symbol:   symbol,
-			Exchange: exchange,
-			Mode:     3, // 3 for Depth
-			Depth:    5,
+		var data map[string]interface{}
+		if err := json.Unmarshal(raw, &data); err != nil {
+			c.logger.Warn("failed to decode websocket message", "error", err)
+			continue
 		}
 
-		log.Printf("Subscribing to %s:%s Depth", exchange, symbol)
-		if err := c.wsConn.WriteJSON(msg); err != nil {
-			return fmt.Errorf("error subscribing to %s:%s: %w", exchange, symbol, err)
+		// Handle status messages
+		if status, ok := data["status"].(string); ok {
+			message, _ := data["message"].(string)
+			if status == "error" {
+				c.logger.Error("websocket error", "error", newAPIError("websocket", 0, status, message, raw))
+			} else if message != "" {
+				c.logger.Debug("websocket status", "status", status, "message", message)
+			}
 		}
-
-		time.Sleep(100 * time.Millisecond)
 	}
-
-	return nil
 }
 
-// UnsubscribeDepth unsubscribes from Market Depth updates
-func (c *Client) UnsubscribeDepth(instruments []Instrument) error {
-	if c.wsConn == nil {
-		return fmt.Errorf("not connected to WebSocket server")
-	}
-
-	// Unsubscribe from each instrument individually
-	for _, instrument := range instruments {
-		symbol := instrument.Symbol
-		exchange := instrument.Exchange
-
-		if symbol == "" && instrument.ExchangeToken != "" {
-			symbol = instrument.ExchangeToken
-		}
+// SubscribeLTP subscribes to Last Traded Price updates. onDataReceived
+// replaces the handler set by an earlier SubscribeLTP call for the same
+// instruments; use Subscribe to register independent handlers.
+func (c *Client) SubscribeLTP(instruments []Instrument, onDataReceived func(interface{})) error {
+	return c.subscribeLegacy(instruments, ModeLTP, 0, onDataReceived)
+}
 
-		if exchange == "" || symbol == "" {
-			log.Printf("Invalid instrument: %+v", instrument)
-			continue
-		}
+// UnsubscribeLTP unsubscribes from LTP updates. Handlers registered with
+// Subscribe are not affected.
+func (c *Client) UnsubscribeLTP(instruments []Instrument) error {
+	return c.unsubscribeLegacy(instruments, ModeLTP)
+}
 
-		msg := SubscriptionMessage{
-			Action:   "unsubscribe",
-			Symbol:   symbol,
-			Exchange: exchange,
-			Mode:     3, // 3 for Depth
-		}
+// SubscribeQuote subscribes to Quote updates
+func (c *Client) SubscribeQuote(instruments []Instrument, onDataReceived func(interface{})) error {
+	return c.subscribeLegacy(instruments, ModeQuote, 0, onDataReceived)
+}
 
-		log.Printf("Unsubscribing from %s:%s Depth", exchange, symbol)
-		if err := c.wsConn.WriteJSON(msg); err != nil {
-			return fmt.Errorf("error unsubscribing from %s:%s: %w", exchange, symbol, err)
-		}
+// UnsubscribeQuote unsubscribes from Quote updates
+func (c *Client) UnsubscribeQuote(instruments []Instrument) error {
+	return c.unsubscribeLegacy(instruments, ModeQuote)
+}
 
-		time.Sleep(100 * time.Millisecond)
+// SubscribeDepth subscribes to Market Depth updates. An optional levels
+// argument subscribes to a deeper book, such as 20 or 50 levels; otherwise
+// DefaultDepthLevels levels are requested.
+func (c *Client) SubscribeDepth(instruments []Instrument, onDataReceived func(interface{}), levels ...int) error {
+	n, err := optionalDepthLevels(levels)
+	if err != nil {
+		return err
 	}
+	return c.subscribeLegacy(instruments, ModeDepth, n, onDataReceived)
+}
 
-	// Remove callback
-	delete(c.callbacks, "depth")
-	return nil
+// UnsubscribeDepth unsubscribes from Market Depth updates
+func (c *Client) UnsubscribeDepth(instruments []Instrument) error {
+	return c.unsubscribeLegacy(instruments, ModeDepth)
 }
 
-// SafeWSClient provides thread-safe WebSocket operations
+// SafeWSClient provides thread-safe WebSocket operations.
+//
+// Deprecated: Client is safe for concurrent use, including its WebSocket
+// methods. Use Client directly.
 type SafeWSClient struct {
 	*Client
 	mu sync.Mutex
 }
 
-// NewSafeWSClient creates a new thread-safe WebSocket client
+// NewSafeWSClient creates a new thread-safe WebSocket client.
+//
+// Deprecated: use NewClient or NewClientWithOptions.
 func NewSafeWSClient(apiKey string, host string, optionalArgs ...interface{}) *SafeWSClient {
 	return &SafeWSClient{
 		Client: NewClient(apiKey, host, optionalArgs...),
@@ -422,10 +572,10 @@ func (s *SafeWSClient) UnsubscribeQuote(instruments []Instrument) error {
 }
 
 // SubscribeDepth thread-safe Depth subscription
-func (s *SafeWSClient) SubscribeDepth(instruments []Instrument, onDataReceived func(interface{})) error {
+func (s *SafeWSClient) SubscribeDepth(instruments []Instrument, onDataReceived func(interface{}), levels ...int) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	return s.Client.SubscribeDepth(instruments, onDataReceived)
+	return s.Client.SubscribeDepth(instruments, onDataReceived, levels...)
 }
 
 // UnsubscribeDepth thread-safe Depth unsubscription
@@ -433,4 +583,4 @@ func (s *SafeWSClient) UnsubscribeDepth(instruments []Instrument) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	return s.Client.UnsubscribeDepth(instruments)
-}
\ No newline at end of file
+}
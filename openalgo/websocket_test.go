@@ -0,0 +1,455 @@
+package openalgo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// fakeWSServer is an OpenAlgo WebSocket server that accepts every API key,
+// acknowledges every subscribe and unsubscribe message and streams ticks of
+// the subscribed instruments
+type fakeWSServer struct {
+	srv      *httptest.Server
+	upgrader websocket.Upgrader
+
+	mu    sync.Mutex
+	conns map[*fakeWSConn]bool
+	// silent stops the server from acknowledging subscription messages
+	silent bool
+	// maxDepth, when set, makes the server reject depth subscriptions
+	// asking for more levels
+	maxDepth int
+	// authError, when set, is sent to reject authentication
+	authError string
+}
+
+// fakeWSConn is a client connection of a fakeWSServer
+type fakeWSConn struct {
+	mu   sync.Mutex // serializes writes
+	conn *websocket.Conn
+	subs map[subKey]bool
+}
+
+func newFakeWSServer(t *testing.T) *fakeWSServer {
+	t.Helper()
+	s := &fakeWSServer{conns: make(map[*fakeWSConn]bool)}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.srv.Close)
+	return s
+}
+
+// url returns the WebSocket URL of s
+func (s *fakeWSServer) url() string {
+	return "ws" + strings.TrimPrefix(s.srv.URL, "http")
+}
+
+// drop closes every client connection without a close handshake
+func (s *fakeWSServer) drop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.conns {
+		c.conn.UnderlyingConn().Close()
+	}
+}
+
+func (s *fakeWSServer) serve(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &fakeWSConn{conn: conn, subs: make(map[subKey]bool)}
+	s.mu.Lock()
+	s.conns[c] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	done := make(chan struct{})
+	defer close(done)
+	go c.streamTicks(done)
+
+	for {
+		var msg SubscriptionMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		switch msg.Action {
+		case "authenticate":
+			s.mu.Lock()
+			authError := s.authError
+			s.mu.Unlock()
+			if authError != "" {
+				c.write(map[string]interface{}{"type": "auth", "status": "error", "message": authError})
+				return
+			}
+			c.write(map[string]interface{}{"type": "auth", "status": "success"})
+		case "subscribe", "unsubscribe":
+			key := subKey{exchange: msg.Exchange, symbol: msg.Symbol, mode: Mode(msg.Mode)}
+			s.mu.Lock()
+			silent, maxDepth := s.silent, s.maxDepth
+			s.mu.Unlock()
+			if msg.Action == "subscribe" && key.mode == ModeDepth && maxDepth > 0 && msg.Depth > maxDepth {
+				c.write(map[string]interface{}{
+					"type":   msg.Action,
+					"status": "error",
+					"subscriptions": []map[string]interface{}{{
+						"symbol": msg.Symbol, "exchange": msg.Exchange, "status": "error",
+						"message": "depth not supported",
+					}},
+				})
+				continue
+			}
+			c.mu.Lock()
+			if msg.Action == "subscribe" {
+				c.subs[key] = true
+			} else {
+				delete(c.subs, key)
+			}
+			c.mu.Unlock()
+
+			if silent {
+				continue
+			}
+			c.write(map[string]interface{}{
+				"type":   msg.Action,
+				"status": "success",
+				"subscriptions": []map[string]interface{}{{
+					"symbol": msg.Symbol, "exchange": msg.Exchange, "status": "success",
+					"mode": msg.Mode, "depth": msg.Depth,
+				}},
+			})
+		}
+	}
+}
+
+func (c *fakeWSConn) write(v interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn.WriteJSON(v)
+}
+
+// streamTicks sends a tick of every subscribed instrument every few
+// milliseconds until done is closed
+func (c *fakeWSConn) streamTicks(done chan struct{}) {
+	ticker := time.NewTicker(2 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+		c.mu.Lock()
+		for key := range c.subs {
+			data := map[string]interface{}{"ltp": 100.5, "volume": 10}
+			if key.mode == ModeDepth {
+				data["depth"] = map[string]interface{}{
+					"buy":  []map[string]interface{}{{"price": 100.4, "quantity": 5}},
+					"sell": []map[string]interface{}{{"price": 100.6, "quantity": 7}},
+				}
+			}
+			raw, _ := json.Marshal(map[string]interface{}{
+				"type": "market_data", "exchange": key.exchange, "symbol": key.symbol,
+				"mode": int(key.mode), "data": data,
+			})
+			if err := c.conn.WriteMessage(websocket.TextMessage, raw); err != nil {
+				c.mu.Unlock()
+				return
+			}
+		}
+		c.mu.Unlock()
+	}
+}
+
+func newTestWSClient(t *testing.T, s *fakeWSServer, opts ...Option) *Client {
+	t.Helper()
+	opts = append([]Option{
+		WithWebSocketURL(s.url()),
+		WithReconnectPolicy(ReconnectPolicy{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond, Multiplier: 2}),
+		WithRateLimits(RateLimits{
+			Orders:        RateLimit{Rate: 10, Burst: 10},
+			Data:          RateLimit{Rate: 50, Burst: 50},
+			Subscriptions: RateLimit{Rate: 5000, Burst: 500},
+		}),
+	}, opts...)
+	c, err := NewClientWithOptions("test-key", s.srv.URL, opts...)
+	if err != nil {
+		t.Fatalf("NewClientWithOptions: %v", err)
+	}
+	if err := c.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { c.Disconnect() })
+	return c
+}
+
+// waitFor polls cond until it holds or timeout passes
+func waitFor(t *testing.T, timeout time.Duration, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestWebSocketConcurrentTraffic subscribes and unsubscribes through every
+// API from several goroutines while ticks stream in and the connection is
+// dropped, and checks that the feed recovers. Run it with -race.
+func TestWebSocketConcurrentTraffic(t *testing.T) {
+	server := newFakeWSServer(t)
+	reconnected := make(chan error, 1)
+	c := newTestWSClient(t, server)
+	c.OnReconnect(func(attempt int, err error) {
+		select {
+		case reconnected <- err:
+		default:
+		}
+	})
+
+	var kept atomic.Int64
+	keep, err := c.SubscribeLTPFunc([]Instrument{{Exchange: "NSE", Symbol: "KEEP"}}, func(LTPTick) { kept.Add(1) })
+	if err != nil {
+		t.Fatalf("SubscribeLTPFunc: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := keep.Ack().Wait(ctx); err != nil {
+		t.Fatalf("Ack().Wait: %v", err)
+	}
+
+	instruments := []Instrument{
+		{Exchange: "NSE", Symbol: "SBIN"},
+		{Exchange: "NSE", Symbol: "INFY"},
+		{Exchange: "NFO", Symbol: "NIFTY24JANFUT"},
+	}
+	var (
+		wg   sync.WaitGroup
+		stop = make(chan struct{})
+	)
+	run := func(worker func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				worker(i)
+			}
+		}()
+	}
+
+	for w := 0; w < 4; w++ {
+		w := w
+		run(func(i int) {
+			sub, err := c.Subscribe(instruments[(w+i)%len(instruments):], ModeQuote, func(interface{}) {})
+			if err != nil {
+				return
+			}
+			time.Sleep(time.Millisecond)
+			sub.Unsubscribe()
+		})
+	}
+	run(func(i int) {
+		sub, err := c.SubscribeDepthLevels(instruments[:1], 5, func(DepthTick) {})
+		if err != nil {
+			return
+		}
+		time.Sleep(time.Millisecond)
+		sub.Unsubscribe()
+	})
+	for w := 0; w < 2; w++ {
+		run(func(i int) {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			stream, err := c.Stream(ctx, instruments, ModeLTP, StreamConfig{BufferSize: 4, Overflow: OverflowDropOldest})
+			if err != nil {
+				return
+			}
+			for range stream.C {
+			}
+		})
+	}
+	run(func(i int) {
+		if err := c.SubscribeLTP(instruments[i%len(instruments):], func(interface{}) {}); err != nil {
+			return
+		}
+		time.Sleep(time.Millisecond)
+		// Legacy unsubscribes need a connection, so retry through a drop
+		for c.UnsubscribeLTP(instruments[i%len(instruments):]) != nil {
+			time.Sleep(5 * time.Millisecond)
+		}
+	})
+
+	// Keep the traffic going before, during and after the drop
+	time.Sleep(100 * time.Millisecond)
+	server.drop()
+	time.Sleep(200 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+
+	select {
+	case err := <-reconnected:
+		if err != nil {
+			t.Errorf("OnReconnect error = %v, want nil", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("OnReconnect was not called")
+	}
+
+	before := kept.Load()
+	waitFor(t, 5*time.Second, "ticks after reconnect", func() bool { return kept.Load() > before+5 })
+
+	if subs := c.activeSubscriptions(); len(subs) != 1 || subs[0].Symbol != "KEEP" {
+		t.Errorf("active subscriptions = %v, want only NSE:KEEP", subs)
+	}
+	for _, key := range []subKey{{exchange: "NSE", symbol: "SBIN", mode: ModeQuote}, {exchange: "NSE", symbol: "SBIN", mode: ModeLTP}} {
+		c.ws.mu.Lock()
+		_, ok := c.ws.subscriptions[key]
+		c.ws.mu.Unlock()
+		if ok {
+			t.Errorf("subscription %+v left behind", key)
+		}
+	}
+}
+
+// TestConnectReplaysSubscriptions drops the connection with reconnection
+// disabled and checks that a manual Connect restores the feed of existing
+// subscriptions and of legacy subscriptions made afterwards
+func TestConnectReplaysSubscriptions(t *testing.T) {
+	server := newFakeWSServer(t)
+	c := newTestWSClient(t, server, WithReconnectPolicy(ReconnectPolicy{Disabled: true}))
+
+	var kept, legacy atomic.Int64
+	keep, err := c.SubscribeLTPFunc([]Instrument{{Exchange: "NSE", Symbol: "KEEP"}}, func(LTPTick) { kept.Add(1) })
+	if err != nil {
+		t.Fatalf("SubscribeLTPFunc: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := keep.Ack().Wait(ctx); err != nil {
+		t.Fatalf("Ack().Wait: %v", err)
+	}
+	waitFor(t, 5*time.Second, "ticks before the drop", func() bool { return kept.Load() > 0 })
+
+	server.drop()
+	waitFor(t, 5*time.Second, "the connection to be lost", func() bool { return c.currentConn() == nil })
+	if err := c.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+
+	before := kept.Load()
+	waitFor(t, 5*time.Second, "ticks after Connect", func() bool { return kept.Load() > before+5 })
+
+	if err := c.SubscribeLTP([]Instrument{{Exchange: "NSE", Symbol: "KEEP"}}, func(interface{}) { legacy.Add(1) }); err != nil {
+		t.Fatalf("SubscribeLTP: %v", err)
+	}
+	waitFor(t, 5*time.Second, "legacy ticks after Connect", func() bool { return legacy.Load() > 5 })
+}
+
+// TestDisconnectClosesStreams checks that Disconnect closes the channel of
+// a stream whose context is never done
+func TestDisconnectClosesStreams(t *testing.T) {
+	server := newFakeWSServer(t)
+	c := newTestWSClient(t, server)
+
+	stream, err := c.Stream(context.Background(), []Instrument{{Exchange: "NSE", Symbol: "SBIN"}}, ModeLTP, StreamConfig{})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	select {
+	case <-stream.C:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no tick before Disconnect")
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		for range stream.C {
+		}
+		close(closed)
+	}()
+	if err := c.Disconnect(); err != nil {
+		t.Fatalf("Disconnect: %v", err)
+	}
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("stream channel still open 1s after Disconnect")
+	}
+
+	c.ws.mu.Lock()
+	n := len(c.ws.streams)
+	c.ws.mu.Unlock()
+	if n != 0 {
+		t.Errorf("%d streams registered after Disconnect, want 0", n)
+	}
+}
+
+// TestReconnectRetriesAuthFailures checks that reconnecting keeps going
+// through an authentication failure that does not blame the API key, and
+// stops on one that does
+func TestReconnectRetriesAuthFailures(t *testing.T) {
+	server := newFakeWSServer(t)
+	var failed atomic.Int64
+	c := newTestWSClient(t, server, WithMetrics(reconnectCounter{failed: &failed}))
+	reconnected := make(chan error, 1)
+	c.OnReconnect(func(attempt int, err error) { reconnected <- err })
+
+	setAuthError := func(msg string) {
+		server.mu.Lock()
+		server.authError = msg
+		server.mu.Unlock()
+	}
+	setAuthError("maximum connections reached")
+	server.drop()
+	waitFor(t, 5*time.Second, "failed reconnect attempts", func() bool { return failed.Load() >= 3 })
+	setAuthError("")
+	select {
+	case err := <-reconnected:
+		if err != nil {
+			t.Errorf("OnReconnect error = %v, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("not reconnected after the authentication failure cleared")
+	}
+
+	setAuthError("Invalid API key")
+	server.drop()
+	waitFor(t, 5*time.Second, "a failed reconnect attempt", func() bool { return failed.Load() >= 4 })
+	setAuthError("")
+	time.Sleep(200 * time.Millisecond)
+	if c.currentConn() != nil {
+		t.Error("reconnected after the API key was rejected, want reconnecting to stop")
+	}
+}
+
+// reconnectCounter is a MetricsCollector counting failed reconnect attempts
+type reconnectCounter struct {
+	nopMetrics
+	failed *atomic.Int64
+}
+
+func (r reconnectCounter) ObserveReconnect(attempt int, err error) {
+	if err != nil {
+		r.failed.Add(1)
+	}
+}
@@ -0,0 +1,107 @@
+package openalgo
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// wsWrite is a message queued for the writer goroutine together with the
+// channel receiving the result of the write
+type wsWrite struct {
+	msg    interface{}
+	result chan error
+}
+
+// wsConn wraps a WebSocket connection whose writes are all performed by a
+// single writer goroutine, as gorilla/websocket supports only one concurrent
+// writer. Reads are performed by the reader goroutine only.
+type wsConn struct {
+	conn      *websocket.Conn
+	heartbeat HeartbeatConfig
+	writes    chan wsWrite
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// newWSConn wraps conn, arms its read deadline and starts its writer
+// goroutine
+func newWSConn(conn *websocket.Conn, heartbeat HeartbeatConfig) *wsConn {
+	wc := &wsConn{
+		conn:      conn,
+		heartbeat: heartbeat,
+		writes:    make(chan wsWrite),
+		done:      make(chan struct{}),
+	}
+	wc.extendReadDeadline()
+	conn.SetPongHandler(func(string) error {
+		wc.extendReadDeadline()
+		return nil
+	})
+	go wc.writeLoop()
+	return wc
+}
+
+// extendReadDeadline pushes the read deadline PongWait into the future. It
+// is called from the reader goroutine only, the pong handler included.
+func (wc *wsConn) extendReadDeadline() {
+	if wc.heartbeat.PongWait > 0 {
+		wc.conn.SetReadDeadline(time.Now().Add(wc.heartbeat.PongWait))
+	}
+}
+
+// writeLoop performs queued writes and sends pings until the connection is
+// closed
+func (wc *wsConn) writeLoop() {
+	var ping <-chan time.Time
+	if wc.heartbeat.PingInterval > 0 {
+		ticker := time.NewTicker(wc.heartbeat.PingInterval)
+		defer ticker.Stop()
+		ping = ticker.C
+	}
+
+	for {
+		select {
+		case w := <-wc.writes:
+			w.result <- wc.conn.WriteJSON(w.msg)
+		case <-ping:
+			deadline := time.Now().Add(wc.heartbeat.PingInterval)
+			if err := wc.conn.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+				// Closing the connection fails the pending read, which
+				// reports the connection as lost
+				wc.conn.Close()
+				return
+			}
+		case <-wc.done:
+			return
+		}
+	}
+}
+
+// write queues msg for the writer goroutine and waits for it to be written
+func (wc *wsConn) write(msg interface{}) error {
+	result := make(chan error, 1)
+	select {
+	case wc.writes <- wsWrite{msg: msg, result: result}:
+	case <-wc.done:
+		return ErrNotConnected
+	}
+	select {
+	case err := <-result:
+		return err
+	case <-wc.done:
+		return ErrNotConnected
+	}
+}
+
+// close stops the writer goroutine and closes the underlying connection. It
+// is safe to call more than once.
+func (wc *wsConn) close() error {
+	var err error
+	wc.closeOnce.Do(func() {
+		close(wc.done)
+		err = wc.conn.Close()
+	})
+	return err
+}